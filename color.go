@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4
+
+import (
+	"reflect"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+type ColorModel string
+
+const (
+	ColorRGB ColorModel = "rgb"
+	ColorHSV ColorModel = "hsv"
+)
+
+// Color is a color literal such as rgb { 12 34 56 } or hsv { 0.1 0.5 0.9 }.
+// An untagged { 12 34 56 } is read as RGB.
+type Color struct {
+	Model  ColorModel
+	Values []float64
+}
+
+func (c *Color) UnmarshalHOI4(dec *hoi4text.Decoder) error {
+	kind, err := dec.PeekKind()
+	if err != nil {
+		return &PeekKindError{err, location(dec)}
+	}
+	var x Color
+	switch kind {
+	case hoi4text.KindTaggedContainer:
+		t, err := dec.ReadToken()
+		if err != nil {
+			return &ReadTokenError{location(dec), err}
+		}
+		switch t.ID() {
+		case hoi4text.TokenRGB:
+			x.Model = ColorRGB
+		case hoi4text.TokenHSV:
+			x.Model = ColorHSV
+		default:
+			return &InvalidTokenError{t, reflect.TypeFor[Color](), location(dec)}
+		}
+	case hoi4text.KindArray:
+		x.Model = ColorRGB
+	default:
+		return &InvalidKindError{kind, reflect.TypeFor[Color](), location(dec)}
+	}
+	dec, err = enterContainer(dec)
+	if err != nil {
+		return err
+	}
+	for dec := range dec.Elements() {
+		t, err := dec.ReadToken()
+		if err != nil {
+			return &ReadTokenError{location(dec), err}
+		}
+		v, ok := tokenFloat(t)
+		if !ok {
+			return &InvalidTokenError{t, reflect.TypeFor[float64](), location(dec)}
+		}
+		x.Values = append(x.Values, v)
+	}
+	if err := iterError(dec); err != nil {
+		return err
+	}
+	*c = x
+	return nil
+}
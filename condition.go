@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4
+
+import (
+	"reflect"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+// Condition holds a value together with the operator that separated it from
+// its key, for example > in has_war_support > 0.5.
+type Condition[T any] struct {
+	Operator hoi4text.TokenID
+	Value    T
+}
+
+func (c *Condition[T]) UnmarshalHOI4(dec *hoi4text.Decoder) error {
+	return unmarshal(dec, reflect.ValueOf(&c.Value))
+}
+
+func (c *Condition[T]) setOperator(op hoi4text.TokenID) {
+	c.Operator = op
+}
+
+type operatorSetter interface {
+	setOperator(op hoi4text.TokenID)
+}
+
+// unmarshalEntry unmarshals the value of an entry whose key and operator
+// have already been read. Only a [Condition] can record an operator other
+// than =, values unmarshaled into any are wrapped in a Condition[any].
+func unmarshalEntry(dec *hoi4text.Decoder, out reflect.Value, op hoi4text.TokenID) error {
+	if s, ok := reflect.TypeAssert[operatorSetter](out); ok {
+		s.setOperator(op)
+		return unmarshal(dec, out)
+	} else if op == hoi4text.TokenEqual {
+		return unmarshal(dec, out)
+	} else if out.Type() != reflect.TypeFor[*any]() {
+		return &InvalidKeyValueSeparatorError{hoi4text.ID(op), location(dec)}
+	}
+	c := Condition[any]{Operator: op}
+	if err := unmarshalAny(dec, reflect.ValueOf(&c.Value).Elem()); err != nil {
+		return err
+	}
+	out.Elem().Set(reflect.ValueOf(c))
+	return nil
+}
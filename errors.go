@@ -19,6 +19,31 @@
 	ErrEmbeddedInterface = errors.New("cannot unmarshal into embedded interface")
 )
 
+// Location identifies where in the input an error occurred.
+type Location struct {
+	Position hoi4text.Position
+	// Path is the path of the value, see [hoi4text.Decoder.Path].
+	Path string
+}
+
+func (l Location) String() string {
+	return string(l.AppendText(nil))
+}
+
+func (l Location) AppendText(dst []byte) []byte {
+	if l.Path == "" {
+		return l.Position.AppendText(dst)
+	}
+	dst = append(dst, l.Path...)
+	dst = append(dst, " ("...)
+	dst = l.Position.AppendText(dst)
+	return append(dst, ')')
+}
+
+func location(dec *hoi4text.Decoder) Location {
+	return Location{dec.Position(), dec.Path()}
+}
+
 type CreateDecoderError struct {
 	Err error
 }
@@ -33,19 +58,20 @@ func (e *CreateDecoderError) Unwrap() error {
 
 type InvalidTypeError struct {
 	Type reflect.Type
+	Location
 }
 
 func (e *InvalidTypeError) Error() string {
-	return fmt.Sprintf("cannot unmarshal into Go value of type %v", e.Type)
+	return fmt.Sprintf("cannot unmarshal into Go value of type %v at %v", e.Type, e.Location)
 }
 
 type ReadTokenError struct {
-	Offset uint64
-	Err    error
+	Location
+	Err error
 }
 
 func (e *ReadTokenError) Error() string {
-	return fmt.Sprintf("failed to read token at offset %d: %v", e.Offset, e.Err)
+	return fmt.Sprintf("failed to read token at %v: %v", e.Location, e.Err)
 }
 
 func (e *ReadTokenError) Unwrap() error {
@@ -54,6 +80,7 @@ func (e *ReadTokenError) Unwrap() error {
 
 type ParseDateError[T int32 | string] struct {
 	Input T
+	Location
 }
 
 func (e *ParseDateError[T]) Error() string {
@@ -65,7 +92,8 @@ func (e *ParseDateError[T]) Error() string {
 	case unsafe.Sizeof(""):
 		dst = appendQuote(dst, *(*string)(unsafe.Pointer(&e.Input)))
 	}
-	dst = append(dst, " as a date"...)
+	dst = append(dst, " as a date at "...)
+	dst = e.Location.AppendText(dst)
 	return string(dst)
 }
 
@@ -83,27 +111,30 @@ func appendQuote(dst []byte, s string) []byte {
 type InvalidTokenError struct {
 	Token hoi4text.Token
 	Type  reflect.Type
+	Location
 }
 
 func (e *InvalidTokenError) Error() string {
-	return fmt.Sprintf("cannot unmarshal token %v into Go value of type %v", e.Token.ID(), e.Type)
+	return fmt.Sprintf("cannot unmarshal token %v into Go value of type %v at %v", e.Token.Name(), e.Type, e.Location)
 }
 
 type OverflowError[T int64 | uint64 | float64 | int32] struct {
 	Value T
 	Type  reflect.Type
+	Location
 }
 
 func (e *OverflowError[T]) Error() string {
-	return fmt.Sprintf("%v(%v) cannot be represented by Go value of type %v", reflect.TypeFor[T](), e.Value, e.Type)
+	return fmt.Sprintf("%v(%v) cannot be represented by Go value of type %v at %v", reflect.TypeFor[T](), e.Value, e.Type, e.Location)
 }
 
 type EnterContainerError struct {
 	Err error
+	Location
 }
 
 func (e *EnterContainerError) Error() string {
-	return fmt.Sprintf("failed to enter the container: %v", e.Err)
+	return fmt.Sprintf("failed to enter the container at %v: %v", e.Location, e.Err)
 }
 
 func (e *EnterContainerError) Unwrap() error {
@@ -111,48 +142,68 @@ func (e *EnterContainerError) Unwrap() error {
 }
 
 type InvalidKeyValueSeparatorError struct {
-	TokenID hoi4text.TokenID
+	Token hoi4text.Token
+	Location
 }
 
 func (e *InvalidKeyValueSeparatorError) Error() string {
-	return fmt.Sprintf("token %v is not a valid key-value separator", e.TokenID)
+	return fmt.Sprintf("token %v at %v is not a valid key-value separator", e.Token.Name(), e.Location)
 }
 
 type InvalidObjectKeyError struct {
 	Token hoi4text.Token
+	Location
 }
 
 func (e *InvalidObjectKeyError) Error() string {
-	return fmt.Sprintf("token %v is not a object key", e.Token.ID())
+	return fmt.Sprintf("token %v at %v is not a object key", e.Token.Name(), e.Location)
 }
 
 type PeekKindError struct {
 	Err error
+	Location
 }
 
 func (e *PeekKindError) Error() string {
-	return fmt.Sprintf("failed to peek kind: %v", e.Err)
+	return fmt.Sprintf("failed to peek kind at %v: %v", e.Location, e.Err)
+}
+
+func (e *PeekKindError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidKindError struct {
+	Kind hoi4text.Kind
+	Type reflect.Type
+	Location
+}
+
+func (e *InvalidKindError) Error() string {
+	return fmt.Sprintf("cannot unmarshal %v into Go value of type %v at %v", e.Kind, e.Type, e.Location)
 }
 
 type InvalidRootTypeError struct {
 	Type reflect.Type
+	Location
 }
 
 func (e *InvalidRootTypeError) Error() string {
-	return fmt.Sprintf("cannot unmarshal root into Go value of type %v", e.Type)
+	return fmt.Sprintf("cannot unmarshal root into Go value of type %v at %v", e.Type, e.Location)
 }
 
 type InvalidScalarError struct {
 	Token hoi4text.Token
+	Location
 }
 
 func (e *InvalidScalarError) Error() string {
-	return fmt.Sprintf("token %v is not a scalar", e.Token.ID())
+	return fmt.Sprintf("token %v at %v is not a scalar", e.Token.Name(), e.Location)
 }
 
 type InvalidEmptyContainerError struct {
 	TokenID hoi4text.TokenID
 	Where   Where
+	Location
 }
 
 func (e *InvalidEmptyContainerError) Error() string {
@@ -161,6 +212,8 @@ func (e *InvalidEmptyContainerError) Error() string {
 	dst = append(dst, e.TokenID.String()...)
 	dst = append(dst, " at "...)
 	dst = append(dst, e.Where...)
+	dst = append(dst, " at "...)
+	dst = e.Location.AppendText(dst)
 	return string(dst)
 }
 
@@ -0,0 +1,298 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4
+
+import (
+	"cmp"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+// Fixed32 is a decimal number with three fractional digits, the format of
+// [hoi4text.TokenF32]. Its integer value is the number of thousandths.
+//
+// Add, Sub and Neg are exact. Mul and Div round half away from zero, and Div
+// panics if the divisor is zero. As with integers, results that overflow wrap
+// around.
+type Fixed32 int32
+
+const fixed32Scale = 1000
+
+// ParseFixed32 parses a decimal number, rounding it to the nearest
+// thousandth.
+func ParseFixed32(s string) (Fixed32, bool) {
+	raw, ok := parseFixed(s, fixed32Scale)
+	if !ok || raw < math.MinInt32 || raw > math.MaxInt32 {
+		return 0, false
+	}
+	return Fixed32(raw), true
+}
+
+func (x Fixed32) Add(y Fixed32) Fixed32 { return x + y }
+func (x Fixed32) Sub(y Fixed32) Fixed32 { return x - y }
+func (x Fixed32) Neg() Fixed32          { return -x }
+
+func (x Fixed32) Mul(y Fixed32) Fixed32 {
+	return Fixed32(mulDiv(int64(x), int64(y), fixed32Scale)) //#nosec G115
+}
+
+func (x Fixed32) Div(y Fixed32) Fixed32 {
+	return Fixed32(mulDiv(int64(x), fixed32Scale, int64(y))) //#nosec G115
+}
+
+func (x Fixed32) Cmp(y Fixed32) int {
+	return cmp.Compare(x, y)
+}
+
+func (x Fixed32) Float64() float64 {
+	return float64(x) / fixed32Scale
+}
+
+func (x Fixed32) String() string {
+	return string(x.AppendText(nil))
+}
+
+// AppendText appends the exact decimal representation of x to dst.
+func (x Fixed32) AppendText(dst []byte) []byte {
+	return appendFixed(dst, int64(x), fixed32Scale, 1, 3)
+}
+
+func (x *Fixed32) UnmarshalHOI4(dec *hoi4text.Decoder) error {
+	t, err := dec.ReadToken()
+	if err != nil {
+		return &ReadTokenError{location(dec), err}
+	}
+	raw, ok := tokenFixed(t, fixed32Scale)
+	if ok && (raw < math.MinInt32 || raw > math.MaxInt32) {
+		return &OverflowError[int64]{raw, reflect.TypeFor[Fixed32](), location(dec)}
+	} else if !ok {
+		return fixedError(dec, t, reflect.TypeFor[Fixed32]())
+	}
+	*x = Fixed32(raw)
+	return nil
+}
+
+// Fixed64 is a binary fixed-point number with 15 fractional bits, the format
+// of [hoi4text.TokenF64]. Its integer value is the number of 1/32768 units.
+//
+// Add, Sub and Neg are exact. Mul and Div round half away from zero, and Div
+// panics if the divisor is zero. As with integers, results that overflow wrap
+// around.
+type Fixed64 int64
+
+const fixed64Scale = 32768
+
+// ParseFixed64 parses a decimal number, rounding it to the nearest multiple
+// of 1/32768.
+func ParseFixed64(s string) (Fixed64, bool) {
+	raw, ok := parseFixed(s, fixed64Scale)
+	return Fixed64(raw), ok
+}
+
+func (x Fixed64) Add(y Fixed64) Fixed64 { return x + y }
+func (x Fixed64) Sub(y Fixed64) Fixed64 { return x - y }
+func (x Fixed64) Neg() Fixed64          { return -x }
+
+func (x Fixed64) Mul(y Fixed64) Fixed64 {
+	return Fixed64(mulDiv(int64(x), int64(y), fixed64Scale))
+}
+
+func (x Fixed64) Div(y Fixed64) Fixed64 {
+	return Fixed64(mulDiv(int64(x), fixed64Scale, int64(y)))
+}
+
+func (x Fixed64) Cmp(y Fixed64) int {
+	return cmp.Compare(x, y)
+}
+
+func (x Fixed64) Float64() float64 {
+	return float64(x) / fixed64Scale
+}
+
+func (x Fixed64) String() string {
+	return string(x.AppendText(nil))
+}
+
+// AppendText appends the exact decimal representation of x to dst. Unlike
+// the game, which shows at most 5 fractional digits, all 15 are kept.
+func (x Fixed64) AppendText(dst []byte) []byte {
+	// 1/32768 == 5^15/10^15
+	return appendFixed(dst, int64(x), fixed64Scale, 30517578125, 15)
+}
+
+func (x *Fixed64) UnmarshalHOI4(dec *hoi4text.Decoder) error {
+	t, err := dec.ReadToken()
+	if err != nil {
+		return &ReadTokenError{location(dec), err}
+	}
+	raw, ok := tokenFixed(t, fixed64Scale)
+	if !ok {
+		return fixedError(dec, t, reflect.TypeFor[Fixed64]())
+	}
+	*x = Fixed64(raw)
+	return nil
+}
+
+func fixedError(dec *hoi4text.Decoder, t hoi4text.Token, typ reflect.Type) error {
+	if f, ok := tokenFloat(t); ok {
+		return &OverflowError[float64]{f, typ, location(dec)}
+	}
+	return &InvalidTokenError{t, typ, location(dec)}
+}
+
+// tokenFixed converts a numeric token to units of 1/scale.
+func tokenFixed(t hoi4text.Token, scale uint64) (int64, bool) {
+	switch t.ID() {
+	case hoi4text.TokenU32:
+		return rescale(false, uint64(t.U32()), scale, 1)
+	case hoi4text.TokenU64:
+		return rescale(false, t.U64(), scale, 1)
+	case hoi4text.TokenI32:
+		neg, abs := splitSign(int64(t.I32()))
+		return rescale(neg, abs, scale, 1)
+	case hoi4text.TokenF32:
+		raw, _ := t.RawF32()
+		neg, abs := splitSign(int64(raw))
+		return rescale(neg, abs, scale, fixed32Scale)
+	case hoi4text.TokenF64:
+		raw, _ := t.RawF64()
+		neg, abs := splitSign(raw)
+		return rescale(neg, abs, scale, fixed64Scale)
+	case hoi4text.TokenI64:
+		neg, abs := splitSign(t.I64())
+		return rescale(neg, abs, scale, 1)
+	case hoi4text.TokenUnquoted:
+		return parseFixed(t.Unquoted(), scale)
+	default:
+		return 0, false
+	}
+}
+
+// parseFixed parses a decimal number into units of 1/scale.
+func parseFixed(s string, scale uint64) (int64, bool) {
+	var neg bool
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, false
+	}
+	var i, f uint64
+	if intPart != "" {
+		var err error
+		if i, err = strconv.ParseUint(intPart, 10, 64); err != nil {
+			return 0, false
+		}
+	}
+	den := uint64(1)
+	if fracPart != "" {
+		if !isDigits(fracPart) {
+			return 0, false
+		}
+		// Digits past the 14th are far below the resolution of either type.
+		fracPart = fracPart[:min(len(fracPart), 14)]
+		f, _ = strconv.ParseUint(fracPart, 10, 64)
+		for range fracPart {
+			den *= 10
+		}
+	}
+	x, ok := rescale(false, i, scale, 1)
+	if !ok {
+		return 0, false
+	}
+	y, _ := rescale(false, f, scale, den)
+	if x > math.MaxInt64-y {
+		return 0, false
+	}
+	if neg {
+		return -(x + y), true
+	}
+	return x + y, true
+}
+
+func isDigits(s string) bool {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// rescale returns ±n*num/den, rounded half away from zero.
+func rescale(neg bool, n, num, den uint64) (int64, bool) {
+	hi, lo := bits.Mul64(n, num)
+	lo, carry := bits.Add64(lo, den/2, 0)
+	hi += carry
+	if hi >= den {
+		return 0, false
+	}
+	q, _ := bits.Div64(hi, lo, den)
+	switch {
+	case neg && q <= 1<<63:
+		return -int64(q), true //#nosec G115
+	case !neg && q <= math.MaxInt64:
+		return int64(q), true
+	default:
+		return 0, false
+	}
+}
+
+// mulDiv returns x*y/den, rounded half away from zero and wrapped to 64
+// bits.
+func mulDiv(x, y, den int64) int64 {
+	negX, absX := splitSign(x)
+	negY, absY := splitSign(y)
+	negD, absD := splitSign(den)
+	if absD == 0 {
+		panic("division by zero")
+	}
+	hi, lo := bits.Mul64(absX, absY)
+	lo, carry := bits.Add64(lo, absD/2, 0)
+	hi += carry
+	// The low 64 bits of the quotient, so that overflow wraps around.
+	q, _ := bits.Div64(hi%absD, lo, absD)
+	if negX != negY != negD {
+		q = -q
+	}
+	return int64(q) //#nosec G115
+}
+
+func splitSign(x int64) (bool, uint64) {
+	if x < 0 {
+		return true, -uint64(x) //#nosec G115
+	}
+	return false, uint64(x)
+}
+
+// appendFixed appends raw/scale in decimal. Multiplying the remainder by
+// mult must give the fraction in units of 10^-digits.
+func appendFixed(dst []byte, raw int64, scale, mult uint64, digits int) []byte {
+	neg, abs := splitSign(raw)
+	if neg {
+		dst = append(dst, '-')
+	}
+	dst = strconv.AppendUint(dst, abs/scale, 10)
+	frac := abs % scale * mult
+	if frac == 0 {
+		return dst
+	}
+	for frac%10 == 0 {
+		frac /= 10
+		digits--
+	}
+	dst = append(dst, '.')
+	s := strconv.FormatUint(frac, 10)
+	for range digits - len(s) {
+		dst = append(dst, '0')
+	}
+	return append(dst, s...)
+}
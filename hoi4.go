@@ -7,6 +7,7 @@
 	"bytes"
 	"io"
 	"reflect"
+	"slices"
 
 	"github.com/antoniszymanski/hoi4-go/hoi4text"
 )
@@ -15,22 +16,70 @@ type Unmarshaler interface {
 	UnmarshalHOI4(dec *hoi4text.Decoder) error
 }
 
-func Unmarshal(in []byte, out any) error {
-	return UnmarshalRead(bytes.NewReader(in), out)
+// Unmarshal decodes a save file held in memory. Unless
+// [hoi4text.WithCopyStrings] is given, strings in out may share memory with
+// in, so in must not be modified while out is in use.
+func Unmarshal(in []byte, out any, opts ...hoi4text.Option) error {
+	v, err := validateValue(out)
+	if err != nil {
+		return err
+	}
+	dec, err := hoi4text.NewDecoderBytes(in, opts...)
+	if err != nil {
+		return &CreateDecoderError{err}
+	}
+	return unmarshalRoot(dec, v)
 }
 
-func UnmarshalRead(in io.Reader, out any) error {
+func UnmarshalRead(in io.Reader, out any, opts ...hoi4text.Option) error {
 	v, err := validateValue(out)
 	if err != nil {
 		return err
 	}
-	dec, err := hoi4text.NewDecoder(in)
+	dec, err := hoi4text.NewDecoder(in, opts...)
 	if err != nil {
 		return &CreateDecoderError{err}
 	}
 	return unmarshalRoot(dec, v)
 }
 
+// UnmarshalFile decodes the save file at path, memory-mapping it where
+// supported. Strings in out are copied, as nothing keeps the mapping
+// reachable once it returns.
+func UnmarshalFile(path string, out any, opts ...hoi4text.Option) (err error) {
+	v, err := validateValue(out)
+	if err != nil {
+		return err
+	}
+	opts = append(slices.Clip(opts), hoi4text.WithCopyStrings())
+	f, err := hoi4text.OpenFile(path, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	dec, err := f.NewDecoder()
+	if err != nil {
+		return &CreateDecoderError{err}
+	}
+	return unmarshalRoot(dec, v)
+}
+
+func UnmarshalScript(in []byte, out any, opts ...hoi4text.Option) error {
+	return UnmarshalScriptRead(bytes.NewReader(in), out, opts...)
+}
+
+func UnmarshalScriptRead(in io.Reader, out any, opts ...hoi4text.Option) error {
+	v, err := validateValue(out)
+	if err != nil {
+		return err
+	}
+	return unmarshalRoot(hoi4text.NewScriptDecoder(in, opts...), v)
+}
+
 func UnmarshalDecode(in *hoi4text.Decoder, out any) error {
 	v, err := validateValue(out)
 	if err != nil {
@@ -39,6 +88,16 @@ func UnmarshalDecode(in *hoi4text.Decoder, out any) error {
 	return unmarshalRoot(in, v)
 }
 
+// UnmarshalValue decodes the next value read by in, such as the value of an
+// entry yielded by [hoi4text.Decoder.Entries].
+func UnmarshalValue(in *hoi4text.Decoder, out any) error {
+	v, err := validateValue(out)
+	if err != nil {
+		return err
+	}
+	return unmarshal(in, v)
+}
+
 func validateValue(i any) (reflect.Value, error) {
 	v := reflect.ValueOf(i)
 	if v.Kind() != reflect.Pointer || v.IsNil() {
@@ -4,9 +4,15 @@
 package hoi4_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -15,6 +21,7 @@
 	"github.com/alecthomas/repr"
 	"github.com/antoniszymanski/hoi4-go"
 	"github.com/antoniszymanski/hoi4-go/hoi4date"
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
@@ -49,6 +56,447 @@ func Test(t *testing.T) {
 	test(t, expected, actual)
 }
 
+func TestText(t *testing.T) {
+	in := []byte(`HOI4txt
+player="FRA"
+date="1936.1.1.12" # start date
+player_countries={
+	"FRA"={
+		user="comagoosie"
+	}
+}
+achievement={ 3 6 19 }
+`)
+	var actual any
+	if err := hoi4.Unmarshal(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]any{
+		"player": {"FRA"},
+		"date":   {"1936.1.1.12"},
+		"player_countries": {map[string][]any{
+			"FRA": {map[string][]any{
+				"user": {"comagoosie"},
+			}},
+		}},
+		"achievement": {[]any{"3", "6", "19"}},
+	}
+	test(t, any(expected), actual)
+}
+
+func TestTextSave(t *testing.T) {
+	in := []byte(`HOI4txt
+player="FRA"
+date="1936.1.1.12"
+player_countries={
+	FRA={
+		user="comagoosie"
+		country_leader=yes
+		id=1
+	}
+}
+achievement={ 3 6 19 }
+`)
+	var actual Save
+	if err := hoi4.Unmarshal(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := Save{
+		Player: "FRA",
+		Date: hoi4date.Date{
+			Year:  1936,
+			Month: 1,
+			Day:   1,
+			Hour:  12,
+		},
+		PlayerCountries: map[string]PlayerCountry{
+			"FRA": {
+				User:          "comagoosie",
+				CountryLeader: true,
+				ID:            1,
+			},
+		},
+		Achievement: []int32{3, 6, 19},
+	}
+	test(t, expected, actual)
+}
+
+func TestScript(t *testing.T) {
+	in := []byte(`focus_tree = {
+	id = german_focus
+	focus = {
+		id = GER_rhineland
+		icon = GFX_goal_generic_occupy_states_ongoing_war
+	}
+}
+`)
+	type Focus struct {
+		ID   string `hoi4:"id"`
+		Icon string `hoi4:"icon"`
+	}
+	type FocusTree struct {
+		ID    string `hoi4:"id"`
+		Focus Focus  `hoi4:"focus"`
+	}
+	type Script struct {
+		FocusTree FocusTree `hoi4:"focus_tree"`
+	}
+	var actual Script
+	if err := hoi4.UnmarshalScript(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := Script{
+		FocusTree: FocusTree{
+			ID: "german_focus",
+			Focus: Focus{
+				ID:   "GER_rhineland",
+				Icon: "GFX_goal_generic_occupy_states_ongoing_war",
+			},
+		},
+	}
+	test(t, expected, actual)
+}
+
+func TestCondition(t *testing.T) {
+	in := []byte(`limit = { tag != GER has_war_support>0.5 is_ai = yes }`)
+	type Limit struct {
+		Tag           hoi4.Condition[string]  `hoi4:"tag"`
+		HasWarSupport hoi4.Condition[float64] `hoi4:"has_war_support"`
+		IsAI          hoi4.Condition[bool]    `hoi4:"is_ai"`
+	}
+	type Script struct {
+		Limit Limit `hoi4:"limit"`
+	}
+	var actual Script
+	if err := hoi4.UnmarshalScript(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := Script{
+		Limit: Limit{
+			Tag:           hoi4.Condition[string]{hoi4text.TokenNotEqual, "GER"},
+			HasWarSupport: hoi4.Condition[float64]{hoi4text.TokenGreater, 0.5},
+			IsAI:          hoi4.Condition[bool]{hoi4text.TokenEqual, true},
+		},
+	}
+	test(t, expected, actual)
+}
+
+func TestColor(t *testing.T) {
+	in := []byte(`color = rgb { 12 34 56 } color_ui = hsv { 0.1 0.5 0.9 } skipped = rgb { 1 2 3 } plain = { 1 2 3 }`)
+	type Country struct {
+		Color   hoi4.Color `hoi4:"color"`
+		ColorUI hoi4.Color `hoi4:"color_ui"`
+		Plain   hoi4.Color `hoi4:"plain"`
+	}
+	var actual Country
+	if err := hoi4.UnmarshalScript(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := Country{
+		Color:   hoi4.Color{hoi4.ColorRGB, []float64{12, 34, 56}},
+		ColorUI: hoi4.Color{hoi4.ColorHSV, []float64{0.1, 0.5, 0.9}},
+		Plain:   hoi4.Color{hoi4.ColorRGB, []float64{1, 2, 3}},
+	}
+	test(t, expected, actual)
+
+	container := func(id hoi4text.TokenID, values ...uint32) []byte {
+		dst := binary.LittleEndian.AppendUint16(nil, uint16(hoi4text.TokenOpen))
+		for _, x := range values {
+			dst = binary.LittleEndian.AppendUint16(dst, uint16(id))
+			dst = binary.LittleEndian.AppendUint32(dst, x)
+		}
+		return binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenClose))
+	}
+	in = appendEntry([]byte(hoi4text.HeaderBin), "color", hoi4text.TokenRGB, container(hoi4text.TokenI32, 12, 34, 56))
+	in = appendEntry(in, "color_ui", hoi4text.TokenHSV, container(hoi4text.TokenF32, 250, 500, 750))
+	actual = Country{}
+	if err := hoi4.Unmarshal(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected = Country{
+		Color:   expected.Color,
+		ColorUI: hoi4.Color{hoi4.ColorHSV, []float64{0.25, 0.5, 0.75}},
+	}
+	test(t, expected, actual)
+}
+
+func TestMapKeys(t *testing.T) {
+	in := []byte(hoi4text.HeaderBin)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenI32))
+	in = binary.LittleEndian.AppendUint32(in, 64)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenQuoted))
+	in = binary.LittleEndian.AppendUint16(in, 6)
+	in = append(in, "Berlin"...)
+	var states map[string]string
+	if err := hoi4.Unmarshal(in, &states); err != nil {
+		t.Fatal(err)
+	}
+	test(t, map[string]string{"64": "Berlin"}, states)
+
+	var out struct {
+		States map[int]string         `hoi4:"states"`
+		Dates  map[hoi4date.Date]bool `hoi4:"dates"`
+		Any    map[any]int            `hoi4:"any"`
+		Fixed  map[hoi4.Fixed32]int   `hoi4:"fixed"`
+	}
+	in = []byte(`states = { 64 = Berlin } dates = { 1936.1.1.12 = yes } any = { a = 1 }`)
+	if err := hoi4.UnmarshalScript(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	test(t, map[int]string{64: "Berlin"}, out.States)
+	test(t, map[hoi4date.Date]bool{{Year: 1936, Month: 1, Day: 1, Hour: 12}: true}, out.Dates)
+	test(t, map[any]int{"a": 1}, out.Any)
+
+	var target *hoi4.InvalidTypeError
+	if err := hoi4.UnmarshalScript([]byte(`fixed = { 1.5 = 1 }`), &out); !errors.As(err, &target) {
+		t.Fatalf("got %v, want an InvalidTypeError", err)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	type Value struct {
+		A hoi4.Fixed32 `hoi4:"a"`
+		B hoi4.Fixed64 `hoi4:"b"`
+	}
+	in := []byte(hoi4text.HeaderBin)
+	in = appendEntry(in, "a", hoi4text.TokenF32, binary.LittleEndian.AppendUint32(nil, 1234))
+	in = appendEntry(in, "b", hoi4text.TokenF64, binary.LittleEndian.AppendUint64(nil, 32769))
+	var actual Value
+	if err := hoi4.Unmarshal(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+	test(t, Value{1234, 32769}, actual)
+	test(t, "1.234", actual.A.String())
+	test(t, "1.000030517578125", actual.B.String())
+
+	if err := hoi4.UnmarshalScript([]byte("a = -0.5 b = 0.25"), &actual); err != nil {
+		t.Fatal(err)
+	}
+	test(t, Value{-500, 8192}, actual)
+	test(t, hoi4.Fixed64(4096), actual.B.Mul(actual.B.Add(actual.B)))
+	test(t, "-0.5", actual.A.String())
+
+	// Overflow wraps around as with integers.
+	maxInt32, maxInt64 := int32(math.MaxInt32), int64(math.MaxInt64)
+	test(t, hoi4.Fixed32(maxInt32*2), hoi4.Fixed32(maxInt32).Mul(2000))
+	test(t, hoi4.Fixed32(maxInt32*1000), hoi4.Fixed32(maxInt32).Div(1))
+	test(t, hoi4.Fixed32(-maxInt32*2), hoi4.Fixed32(-maxInt32).Mul(2000))
+	test(t, hoi4.Fixed64(maxInt64*2), hoi4.Fixed64(maxInt64).Mul(2*32768))
+	test(t, hoi4.Fixed64(maxInt64*32768), hoi4.Fixed64(maxInt64).Div(1))
+}
+
+func appendEntry(dst []byte, key string, id hoi4text.TokenID, value []byte) []byte {
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenQuoted))
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(len(key))) //#nosec G115
+	dst = append(dst, key...)
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenEqual))
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(id))
+	return append(dst, value...)
+}
+
+func TestTokenResolver(t *testing.T) {
+	in := binary.LittleEndian.AppendUint16([]byte(hoi4text.HeaderBin), 0xfff0)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+	in = binary.LittleEndian.AppendUint16(in, 0xfff1)
+	resolver := hoi4text.TokenMap{0xfff0: "key", 0xfff1: "value"}
+	var actual map[string]string
+	if err := hoi4.Unmarshal(in, &actual, hoi4text.WithTokenResolver(resolver)); err != nil {
+		t.Fatal(err)
+	}
+	test(t, map[string]string{"key": "value"}, actual)
+	if err := hoi4.Unmarshal(in, &actual); err == nil {
+		t.Fatal("expected an error without the resolver")
+	}
+
+	// Errors name ID tokens with the resolver.
+	var containers map[string]struct{}
+	err := hoi4.Unmarshal(in, &containers, hoi4text.WithTokenResolver(resolver))
+	want := "failed to enter the container at key (offset 4): unexpected token value at the beginning of a container at offset 4"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+	in = binary.LittleEndian.AppendUint16(in, 0xfff0)
+	in = binary.LittleEndian.AppendUint16(in, 0xfff1)
+	err = hoi4.Unmarshal(in, &actual, hoi4text.WithTokenResolver(resolver))
+	want = "token value at offset 8 is not a valid key-value separator"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+}
+
+func TestUnknownTokens(t *testing.T) {
+	in := []byte(hoi4text.HeaderBin)
+	for _, v := range []uint16{
+		0xfff0, uint16(hoi4text.TokenEqual), uint16(hoi4text.TokenOpen),
+		0xfff2, uint16(hoi4text.TokenEqual), uint16(hoi4text.TokenOpen), uint16(hoi4text.TokenClose),
+		0xfff1, uint16(hoi4text.TokenEqual), 0xfff3,
+		uint16(hoi4text.TokenClose),
+		0xfff2, uint16(hoi4text.TokenEqual), 0xfff1,
+	} {
+		in = binary.LittleEndian.AppendUint16(in, v)
+	}
+	resolver := hoi4text.TokenMap{0xfff0: "country", 0xfff1: "name"}
+	dec, err := hoi4text.NewDecoderBytes(in, hoi4text.WithTokenResolver(resolver), hoi4text.WithUnknownTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The value of name is skipped, as there is no field for it.
+	var actual struct {
+		Country struct{} `hoi4:"country"`
+	}
+	if err := hoi4.UnmarshalDecode(dec, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[hoi4text.TokenID]hoi4text.UnknownToken{
+		0xfff2: {ID: 0xfff2, Count: 2, Position: hoi4text.Position{Offset: 6}, Path: []string{"country"}},
+		0xfff3: {ID: 0xfff3, Count: 1, Position: hoi4text.Position{Offset: 18}, Path: []string{"country", "name"}},
+	}
+	test(t, expected, dec.UnknownTokens())
+
+	// Unknown values are recorded and leave the field unchanged.
+	var withName struct {
+		Country struct {
+			Name string `hoi4:"name"`
+		} `hoi4:"country"`
+	}
+	dec, err = hoi4text.NewDecoderBytes(in, hoi4text.WithTokenResolver(resolver), hoi4text.WithUnknownTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hoi4.UnmarshalDecode(dec, &withName); err != nil {
+		t.Fatal(err)
+	}
+	test(t, "", withName.Country.Name)
+	test(t, expected, dec.UnknownTokens())
+
+	if err := hoi4.Unmarshal(in, &withName, hoi4text.WithTokenResolver(resolver)); err == nil {
+		t.Fatal("expected an error without WithUnknownTokens")
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	in := "id = 1\nname = { \"GER\" }\n"
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in), hoi4text.WithFilename("history/GER.txt"))
+	var out struct {
+		Name string `hoi4:"name"`
+	}
+	err := hoi4.UnmarshalDecode(dec, &out)
+	var target *hoi4.InvalidTokenError
+	if !errors.As(err, &target) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := hoi4text.Position{File: "history/GER.txt", Offset: 14, Line: 2, Column: 8}
+	if target.Position != want {
+		t.Fatalf("got %v, want %v", target.Position, want)
+	}
+}
+
+func TestErrorPath(t *testing.T) {
+	in := "politics = { parties = { { popularity = 1 } { popularity = \"x\" } } }"
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in))
+	var out struct {
+		Politics struct {
+			Parties []struct {
+				Popularity int `hoi4:"popularity"`
+			} `hoi4:"parties"`
+		} `hoi4:"politics"`
+	}
+	err := hoi4.UnmarshalDecode(dec, &out)
+	want := `cannot unmarshal token quoted into Go value of type int at politics.parties[1].popularity (1:60)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+}
+
+func TestNonIntegral(t *testing.T) {
+	var out struct {
+		A int  `hoi4:"a"`
+		B uint `hoi4:"b"`
+	}
+	if err := hoi4.UnmarshalScript([]byte("a = 1e3 b = 2.0"), &out); err != nil {
+		t.Fatal(err)
+	}
+	test(t, 1000, out.A)
+	test(t, uint(2), out.B)
+	for _, in := range []string{"a = 1.7", "b = 1.7"} {
+		var target *hoi4.InvalidTokenError
+		if err := hoi4.UnmarshalScript([]byte(in), &out); !errors.As(err, &target) {
+			t.Fatalf("%s: unexpected error: %v", in, err)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	in := []byte(hoi4text.HeaderTxt + `name = "Germany" ideas = { a b c } units = { { { x = 1 } } }`)
+	for _, tt := range []struct {
+		limits hoi4text.Limits
+		limit  string
+	}{
+		{hoi4text.Limits{}, ""},
+		{hoi4text.Limits{MaxDepth: 2}, "MaxDepth"},
+		{hoi4text.Limits{MaxTokens: 10}, "MaxTokens"},
+		{hoi4text.Limits{MaxStringBytes: 5}, "MaxStringBytes"},
+		{hoi4text.Limits{MaxContainerElements: 2}, "MaxContainerElements"},
+		{hoi4text.Limits{MaxInputSize: 20}, "MaxInputSize"},
+	} {
+		var out any
+		err := hoi4.Unmarshal(in, &out, hoi4text.WithLimits(tt.limits))
+		var limitErr *hoi4text.LimitExceededError
+		if tt.limit == "" && err != nil {
+			t.Fatal(err)
+		} else if tt.limit != "" && (!errors.As(err, &limitErr) || limitErr.Limit != tt.limit) {
+			t.Fatalf("got %v, want %s exceeded", err, tt.limit)
+		}
+	}
+
+	// The offset of MaxInputSize does not count the header, which scripts
+	// lack.
+	opt := hoi4text.WithLimits(hoi4text.Limits{MaxInputSize: 20})
+	for _, tt := range []struct {
+		name   string
+		err    error
+		offset uint64
+	}{
+		{"save", hoi4.Unmarshal(in, new(any), opt), 20 - uint64(hoi4text.HeaderLen)},
+		{"script", hoi4.UnmarshalScript(in[hoi4text.HeaderLen:], new(any), opt), 20},
+	} {
+		var limitErr *hoi4text.LimitExceededError
+		if !errors.As(tt.err, &limitErr) || limitErr.Position.Offset != tt.offset {
+			t.Fatalf("%s: got %v, want MaxInputSize exceeded at offset %d", tt.name, tt.err, tt.offset)
+		}
+	}
+}
+
+func TestUnmarshalFile(t *testing.T) {
+	bin := appendEntry([]byte(hoi4text.HeaderBin), "player", hoi4text.TokenQuoted, []byte("\x03\x00FRA"))
+	bin = appendEntry(bin, "date", hoi4text.TokenQuoted, []byte("\x0b\x001936.1.1.12"))
+	for name, in := range map[string][]byte{
+		"text":   []byte("HOI4txt\nplayer=\"FRA\"\ndate=\"1936.1.1.12\"\n"),
+		"binary": bin,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.hoi4")
+			if err := os.WriteFile(path, in, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			var actual Save
+			if err := hoi4.UnmarshalFile(path, &actual); err != nil {
+				t.Fatal(err)
+			}
+			// The strings must outlive the file.
+			if err := os.WriteFile(path, bytes.Repeat([]byte{'x'}, len(in)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			expected := Save{
+				Player: "FRA",
+				Date:   hoi4date.Date{Year: 1936, Month: 1, Day: 1, Hour: 12},
+			}
+			test(t, expected, actual)
+		})
+	}
+}
+
 func test[T any](t *testing.T, expected, actual T) {
 	t.Helper()
 	if reflect.DeepEqual(expected, actual) {
@@ -113,3 +561,50 @@ type PlayerCountry struct {
 	defer resp.Body.Close() //nolint:errcheck
 	return io.ReadAll(resp.Body)
 })
+
+func TestUnmarshalValue(t *testing.T) {
+	in := `date = 1936.1.1 states = { 1 = { name = "Corsica" } 2 = { name = "Brittany" } }`
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in))
+	var names []string
+	for key, dec := range dec.Entries() {
+		if key.String() != "states" {
+			continue
+		}
+		states, err := dec.EnterContainer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dec := range states.Entries() {
+			var state struct {
+				Name string `hoi4:"name"`
+			}
+			if err := hoi4.UnmarshalValue(dec, &state); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, state.Name)
+		}
+		if err := states.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	test(t, []string{"Corsica", "Brittany"}, names)
+}
+
+func TestStrayRootClose(t *testing.T) {
+	in := []byte("a = 1 } b = 2")
+	want := "failed to read token at a (1:7): unexpected token } at the beginning of a value at 1:7"
+	var s struct {
+		A int `hoi4:"a"`
+		B int `hoi4:"b"`
+	}
+	var m map[string]int
+	var a any
+	for _, out := range []any{&s, &m, &a} {
+		if err := hoi4.UnmarshalScript(in, out); err == nil || err.Error() != want {
+			t.Fatalf("got %v, want %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hoi4checksum reads the checksum entry of a save and detects edits
+// to a save.
+//
+// The checksum written by the game identifies the game version and the
+// active mods, it is not a digest of the save itself, and the game does not
+// record one. [Matches] checks the game data a save was made with. To detect
+// edits, a [Digest] of the save is computed when it is submitted and later
+// passed to [Verify], which recomputes it and names the sections that
+// changed.
+package hoi4checksum
+
+import (
+	"errors"
+	"io"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+var ErrNotFound = errors.New("checksum not found")
+
+// Checksum is the top-level checksum entry of a save.
+type Checksum struct {
+	Value string
+	// Position of the value token.
+	Position hoi4text.Position
+}
+
+// Extract returns the checksum of a save read from r. Reading stops at the
+// checksum entry, which comes near the beginning of the save.
+func Extract(r io.Reader, opts ...hoi4text.Option) (Checksum, error) {
+	br, err := hoi4text.NewBufferedReader(r, opts...)
+	if err != nil {
+		return Checksum{}, err
+	}
+	var depth int
+	var key bool
+	for {
+		t, err := br.ReadToken()
+		if err == io.EOF {
+			return Checksum{}, ErrNotFound
+		} else if err != nil {
+			return Checksum{}, err
+		}
+		switch id := t.ID(); {
+		case id == hoi4text.TokenOpen:
+			depth++
+		case id == hoi4text.TokenClose:
+			depth--
+		case key && id == hoi4text.TokenEqual:
+			return readValue(br)
+		}
+		key = depth == 0 && isChecksumKey(t)
+	}
+}
+
+func readValue(r *hoi4text.BufferedReader) (Checksum, error) {
+	t, err := r.ReadToken()
+	if err == io.EOF {
+		return Checksum{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return Checksum{}, err
+	}
+	pos := r.Position()
+	if t.ID() != hoi4text.TokenQuoted {
+		return Checksum{}, &hoi4text.UnexpectedTokenError{Token: t, Where: hoi4text.BeginningOfValue, Position: pos}
+	}
+	return Checksum{t.Quoted(), pos}, nil
+}
+
+func isChecksumKey(t hoi4text.Token) bool {
+	switch id := t.ID(); {
+	case id == hoi4text.TokenUnquoted:
+		return t.Unquoted() == "checksum"
+	case id.IsID():
+		return t.Resolve() == "checksum"
+	default:
+		return false
+	}
+}
+
+// Matches reports whether the checksum stored in the save read from r is
+// expected. It only compares the stored value, use [Verify] to detect edits
+// to the save.
+func Matches(r io.Reader, expected string, opts ...hoi4text.Option) (bool, error) {
+	c, err := Extract(r, opts...)
+	if err != nil {
+		return false, err
+	}
+	return c.Value == expected, nil
+}
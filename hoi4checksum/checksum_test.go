@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4checksum_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4checksum"
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestExtract(t *testing.T) {
+	const checksumID = 0x179 // checksum
+	in := []byte(hoi4text.HeaderBin)
+	in = binary.LittleEndian.AppendUint16(in, 0x1234)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenOpen))
+	in = binary.LittleEndian.AppendUint16(in, checksumID)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+	in = appendString(in, "nested")
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenClose))
+	in = binary.LittleEndian.AppendUint16(in, checksumID)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+	in = appendString(in, "abc123")
+
+	for name, in := range map[string][]byte{
+		"binary": in,
+		"text":   []byte(hoi4text.HeaderTxt + "\nplayer={ checksum=\"nested\" }\nchecksum=\"abc123\"\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := hoi4checksum.Extract(bytes.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			} else if c.Value != "abc123" {
+				t.Fatalf("got %q, want %q", c.Value, "abc123")
+			}
+			if ok, err := hoi4checksum.Matches(bytes.NewReader(in), "abc123"); err != nil || !ok {
+				t.Fatalf("got %v, %v, want true", ok, err)
+			}
+			if ok, err := hoi4checksum.Matches(bytes.NewReader(in), "def456"); err != nil || ok {
+				t.Fatalf("got %v, %v, want false", ok, err)
+			}
+		})
+	}
+	_, err := hoi4checksum.Extract(strings.NewReader(hoi4text.HeaderTxt + "date=1936.1.1.12"))
+	if err != hoi4checksum.ErrNotFound {
+		t.Fatalf("got %v, want %v", err, hoi4checksum.ErrNotFound)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	entry := func(dst []byte, key uint16, value string) []byte {
+		dst = binary.LittleEndian.AppendUint16(dst, key)
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenEqual))
+		return appendString(dst, value)
+	}
+	bin := func(player string) []byte {
+		in := entry([]byte(hoi4text.HeaderBin), 0x179, "abc123") // checksum
+		in = binary.LittleEndian.AppendUint16(in, 0x1234)
+		in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+		in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenOpen))
+		in = entry(in, 0x179, "nested")
+		in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenClose))
+		return entry(in, 0x2a35, player) // player
+	}
+	text := func(player string) []byte {
+		return []byte(hoi4text.HeaderTxt + "\nchecksum=\"abc123\"\nx={ checksum=\"nested\" }\nplayer=\"" + player + "\"\n")
+	}
+	offsets := map[string]string{
+		"binary": "checksum@0 <unknown: 4660>@14 player@36",
+		"text":   "checksum@1 x@19 player@43",
+	}
+	for name, save := range map[string]func(string) []byte{"binary": bin, "text": text} {
+		t.Run(name, func(t *testing.T) {
+			expected, err := hoi4checksum.Compute(bytes.NewReader(save("FRA")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range expected {
+				got = append(got, s.Key+"@"+strconv.FormatUint(s.Offset, 10))
+			}
+			if strings.Join(got, " ") != offsets[name] {
+				t.Fatalf("got sections %q, want %q", strings.Join(got, " "), offsets[name])
+			}
+			if err := hoi4checksum.Verify(bytes.NewReader(save("FRA")), expected); err != nil {
+				t.Fatal(err)
+			}
+			err = hoi4checksum.Verify(bytes.NewReader(save("GER")), expected)
+			if want := "save differs from its digest in player"; err == nil || err.Error() != want {
+				t.Fatalf("got %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenQuoted))
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(len(s))) //#nosec G115
+	return append(dst, s...)
+}
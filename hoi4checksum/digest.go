@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4checksum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+// Digest is a digest of the content of a save, with one SHA-256 sum per
+// top-level entry.
+type Digest []Section
+
+// Section is a top-level entry of a save.
+type Section struct {
+	Key string
+	// Offset of the key after the header.
+	Offset uint64
+	// Sum of the bytes from the key to the next top-level entry.
+	Sum [sha256.Size]byte
+}
+
+// Compute returns the digest of the save read from r.
+func Compute(r io.Reader, opts ...hoi4text.Option) (Digest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	br, err := hoi4text.NewBufferedReader(bytes.NewReader(data), opts...)
+	if err != nil {
+		return nil, err
+	}
+	data = data[hoi4text.HeaderLen:]
+	var d Digest
+	var depth int
+	var entry bool // whether the value of the last key is unread
+	for {
+		t, err := br.ReadToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch id := t.ID(); {
+		case id == hoi4text.TokenOpen:
+			depth++
+			entry = false
+		case id == hoi4text.TokenClose:
+			depth--
+		case depth > 0, entry && (id.IsOperator() || id.IsTag()):
+		case entry:
+			entry = false
+		default:
+			d = append(d, Section{Key: sectionKey(t), Offset: br.Position().Offset})
+			entry = true
+		}
+	}
+	for i := range d {
+		end := uint64(len(data))
+		if i+1 < len(d) {
+			end = d[i+1].Offset
+		}
+		d[i].Sum = sha256.Sum256(data[d[i].Offset:end])
+	}
+	return d, nil
+}
+
+func sectionKey(t hoi4text.Token) string {
+	switch t.ID() {
+	case hoi4text.TokenQuoted:
+		return t.Quoted()
+	case hoi4text.TokenUnquoted:
+		return t.Unquoted()
+	default:
+		return t.String()
+	}
+}
+
+// Diff returns the keys of the sections of d that differ from expected,
+// followed by the keys of the sections of expected that d is missing.
+func (d Digest) Diff(expected Digest) []string {
+	var keys []string
+	for i, s := range d {
+		if i >= len(expected) || s.Key != expected[i].Key || s.Sum != expected[i].Sum {
+			keys = append(keys, s.Key)
+		}
+	}
+	for _, s := range expected[min(len(d), len(expected)):] {
+		keys = append(keys, s.Key)
+	}
+	return keys
+}
+
+// MismatchError is returned by [Verify] when a save differs from its
+// expected digest.
+type MismatchError struct {
+	// Keys of the sections that changed, see [Digest.Diff].
+	Changed []string
+}
+
+func (e *MismatchError) Error() string {
+	var dst []byte
+	dst = append(dst, "save differs from its digest in "...)
+	for i, key := range e.Changed {
+		if i > 0 {
+			dst = append(dst, ", "...)
+		}
+		dst = append(dst, key...)
+	}
+	return string(dst)
+}
+
+// Verify computes the digest of the save read from r and compares it with
+// expected, which is usually computed when the save is submitted. It returns
+// a [*MismatchError] naming the changed sections if they differ.
+func Verify(r io.Reader, expected Digest, opts ...hoi4text.Option) error {
+	d, err := Compute(r, opts...)
+	if err != nil {
+		return err
+	}
+	if changed := d.Diff(expected); len(changed) > 0 {
+		return &MismatchError{changed}
+	}
+	return nil
+}
@@ -6,21 +6,29 @@
 import (
 	"encoding/binary"
 	"io"
-	"math"
 )
 
 type BinaryReader struct {
 	r      io.Reader
+	data   []byte // input after the header, if read from memory
+	mem    bool
 	buf    []byte
 	offset uint64
+	start  uint64
+	opts   options
 }
 
 func (r *BinaryReader) Offset() uint64 {
 	return r.offset
 }
 
+func (r *BinaryReader) Position() Position {
+	return Position{File: r.opts.filename, Offset: r.start}
+}
+
 func (r *BinaryReader) ReadToken() (Token, error) {
 	var t Token
+	r.start = r.offset
 	id, err := r.readID()
 	if err != nil {
 		return t, err
@@ -65,17 +73,17 @@ func (r *BinaryReader) ReadToken() (Token, error) {
 		}
 		t = Unquoted(v)
 	case TokenF32:
-		v, err := r.readF32()
+		v, err := r.readI32()
 		if err != nil {
 			return t, err
 		}
-		t = F32(v)
+		t = FixedF32(v)
 	case TokenF64:
-		v, err := r.readF64()
+		v, err := r.readI64()
 		if err != nil {
 			return t, err
 		}
-		t = F64(v)
+		t = FixedF64(v)
 	case TokenI64:
 		v, err := r.readI64()
 		if err != nil {
@@ -83,12 +91,13 @@ func (r *BinaryReader) ReadToken() (Token, error) {
 		}
 		t = I64(v)
 	default:
-		t = ID(id)
+		t = idToken(id, r.opts.resolver)
 	}
 	return t, nil
 }
 
 func (r *BinaryReader) SkipToken() (TokenID, error) {
+	r.start = r.offset
 	id, err := r.readID()
 	if err != nil {
 		return TokenInvalid, err
@@ -113,6 +122,9 @@ func (r *BinaryReader) SkipToken() (TokenID, error) {
 
 func (r *BinaryReader) readID() (TokenID, error) {
 	v, err := r.readU16()
+	if id := TokenID(v); err == nil && id.isTextOnly() {
+		return TokenInvalid, &UnexpectedTokenError{ID(id), BinaryInput, r.Position()}
+	}
 	return TokenID(v), err
 }
 
@@ -173,27 +185,18 @@ func (r *BinaryReader) readString() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(b), nil
-}
-
-func (r *BinaryReader) readF32() (float32, error) {
-	i, err := r.readI32()
-	if err != nil {
-		return 0, err
-	}
-	return float32(i) / 1000.0, nil
-}
-
-func (r *BinaryReader) readF64() (float64, error) {
-	i, err := r.readI64()
-	if err != nil {
-		return 0, err
-	}
-	val := float64(i) / 32768.0
-	return math.Floor(val*10_0000.0) / 10_0000.0, nil
+	return decodeString(b, r.opts.encoding, false, r.mem && !r.opts.copy), nil
 }
 
 func (r *BinaryReader) read(length int) ([]byte, error) {
+	if r.mem {
+		b, err := r.slice(length)
+		if err != nil {
+			return nil, err
+		}
+		r.offset += uint64(length) //#nosec G115
+		return b, nil
+	}
 	r.buf = resize(r.buf, length)
 	if _, err := io.ReadFull(r.r, r.buf); err != nil {
 		return nil, err
@@ -202,7 +205,28 @@ func (r *BinaryReader) read(length int) ([]byte, error) {
 	return r.buf, nil
 }
 
+// slice returns the next length bytes of an in-memory input, with the same
+// errors as [io.ReadFull].
+func (r *BinaryReader) slice(length int) ([]byte, error) {
+	rest := r.data[r.offset:]
+	switch {
+	case length <= len(rest):
+		return rest[:length:length], nil
+	case len(rest) == 0:
+		return nil, io.EOF
+	default:
+		return nil, io.ErrUnexpectedEOF
+	}
+}
+
 func (r *BinaryReader) skip(n int) error {
+	if r.mem {
+		if _, err := r.slice(n); err != nil {
+			return err
+		}
+		r.offset += uint64(n) //#nosec G115
+		return nil
+	}
 	var err error
 	switch rr := r.r.(type) {
 	case interface{ Discard(n int) (int, error) }:
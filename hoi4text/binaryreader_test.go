@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestReaderBytes(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  []hoi4text.Option
+		alias bool
+	}{
+		{"alias", nil, true},
+		{"copy", []hoi4text.Option{hoi4text.WithCopyStrings()}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			in := appendString([]byte(hoi4text.HeaderBin), hoi4text.TokenQuoted, "FRA")
+			r, err := hoi4text.NewReaderBytes(in, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tok, err := r.ReadToken()
+			if err != nil {
+				t.Fatal(err)
+			} else if tok.Quoted() != "FRA" {
+				t.Fatalf("got %v, want %q", tok, "FRA")
+			}
+			in[len(in)-1] = 'G'
+			if got := tok.Quoted() == "FRG"; got != tt.alias {
+				t.Fatalf("string aliases input: %v, want %v", got, tt.alias)
+			}
+			if _, err := r.ReadToken(); err == nil {
+				t.Fatal("expected EOF")
+			}
+		})
+	}
+}
+
+func appendString(dst []byte, id hoi4text.TokenID, s string) []byte {
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(id))
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(len(s))) //#nosec G115
+	return append(dst, s...)
+}
+
+func TestBinaryTextOnlyTokens(t *testing.T) {
+	in := binary.LittleEndian.AppendUint16([]byte(hoi4text.HeaderBin), 0x1234)
+	in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenLess))
+	r, err := hoi4text.NewReaderBytes(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadToken(); err != nil {
+		t.Fatal(err)
+	}
+	want := "unexpected token < at binary input at offset 2"
+	if _, err := r.ReadToken(); err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+	in = binary.LittleEndian.AppendUint16([]byte(hoi4text.HeaderBin), uint16(hoi4text.TokenComment))
+	if r, err = hoi4text.NewReaderBytes(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadToken(); err == nil {
+		t.Fatal("read a comment from binary input")
+	}
+}
@@ -11,6 +11,7 @@
 type BufferedReader struct {
 	r       Reader
 	offset  uint64
+	pos     Position
 	buf     []bufferedToken
 	peekBuf []bufferedToken
 }
@@ -19,10 +20,11 @@ type bufferedToken struct {
 	token  Token
 	err    error
 	offset uint64
+	pos    Position
 }
 
-func NewBufferedReader(r io.Reader) (*BufferedReader, error) {
-	tr, err := NewReader(r)
+func NewBufferedReader(r io.Reader, opts ...Option) (*BufferedReader, error) {
+	tr, err := NewReader(r, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -33,15 +35,22 @@ func (br *BufferedReader) Offset() uint64 {
 	return br.offset
 }
 
+func (br *BufferedReader) Position() Position {
+	return br.pos
+}
+
 func (br *BufferedReader) ReadToken() (Token, error) {
 	if len(br.buf) > 0 {
 		last := br.buf[len(br.buf)-1]
 		br.buf = br.buf[:len(br.buf)-1]
-		br.offset = last.offset
+		br.offset, br.pos = last.offset, last.pos
 		return last.token, last.err
 	}
 	t, err := br.r.ReadToken()
-	br.offset = br.r.Offset()
+	for err == nil && t.ID().IsTrivia() {
+		t, err = br.r.ReadToken()
+	}
+	br.offset, br.pos = br.r.Offset(), position(br.r)
 	return t, err
 }
 
@@ -49,14 +58,27 @@ func (br *BufferedReader) SkipToken() (TokenID, error) {
 	if len(br.buf) > 0 {
 		last := br.buf[len(br.buf)-1]
 		br.buf = br.buf[:len(br.buf)-1]
-		br.offset = last.offset
+		br.offset, br.pos = last.offset, last.pos
 		return last.token.ID(), last.err
 	}
 	id, err := SkipToken(br.r)
-	br.offset = br.r.Offset()
+	for err == nil && id.IsTrivia() {
+		id, err = SkipToken(br.r)
+	}
+	br.offset, br.pos = br.r.Offset(), position(br.r)
 	return id, err
 }
 
+// unread pushes back a token that was just read.
+func (br *BufferedReader) unread(t Token, err error) {
+	br.buf = append(br.buf, bufferedToken{
+		token:  t,
+		err:    err,
+		offset: br.offset,
+		pos:    br.pos,
+	})
+}
+
 func (br *BufferedReader) PeekKind() (Kind, error) {
 	if br.offset == 0 {
 		return KindRoot, nil
@@ -65,21 +87,26 @@ func (br *BufferedReader) PeekKind() (Kind, error) {
 	defer p.Close()
 	if id, err := p.SkipToken(); err != nil {
 		return KindInvalid, err
-	} else if id == TokenEqual || id == TokenClose {
-		return KindInvalid, &UnexpectedTokenError{id, BeginningOfValue, br.offset}
+	} else if id.IsOperator() || id == TokenClose {
+		return KindInvalid, &UnexpectedTokenError{ID(id), BeginningOfValue, br.pos}
+	} else if id.IsTag() {
+		if id, err := p.SkipToken(); err == nil && id == TokenOpen {
+			return KindTaggedContainer, nil
+		}
+		return KindScalar, nil
 	} else if id != TokenOpen {
 		return KindScalar, nil
 	}
 	if id, err := p.SkipToken(); err != nil {
 		return KindInvalid, err
-	} else if id == TokenEqual {
-		return KindInvalid, &UnexpectedTokenError{id, FirstTokenOfValue, br.offset}
+	} else if id.IsOperator() {
+		return KindInvalid, &UnexpectedTokenError{ID(id), FirstTokenOfValue, br.pos}
 	} else if id == TokenClose {
 		return KindEmptyContainer, nil
 	}
 	if id, err := p.SkipToken(); err != nil {
 		return KindInvalid, err
-	} else if id != TokenEqual {
+	} else if !id.IsOperator() {
 		return KindArray, nil
 	} else {
 		return KindObject, nil
@@ -95,6 +122,7 @@ func (br *BufferedReader) PeekKind() (Kind, error) {
 	KindEmptyContainer
 	KindArray
 	KindObject
+	KindTaggedContainer
 )
 
 func (k Kind) String() string {
@@ -109,29 +137,40 @@ func (k Kind) String() string {
 		return "array"
 	case KindObject:
 		return "object"
+	case KindTaggedContainer:
+		return "tagged container"
 	default:
 		return "invalid"
 	}
 }
 
 func (br *BufferedReader) Peek() Peek {
-	return Peek{br}
+	return Peek{br, br.offset, br.pos}
 }
 
+// Peek reads tokens ahead of a [BufferedReader] without consuming them.
+// Close returns the tokens to the reader and restores its offset.
 type Peek struct {
-	br *BufferedReader
+	br     *BufferedReader
+	offset uint64
+	pos    Position
 }
 
 func (p Peek) Offset() uint64 {
 	return p.br.offset
 }
 
+func (p Peek) Position() Position {
+	return p.br.pos
+}
+
 func (p Peek) ReadToken() (Token, error) {
 	t, err := p.br.ReadToken()
 	p.br.peekBuf = append(p.br.peekBuf, bufferedToken{
 		token:  t,
 		err:    err,
-		offset: p.br.Offset(),
+		offset: p.br.offset,
+		pos:    p.br.pos,
 	})
 	return t, err
 }
@@ -145,4 +184,5 @@ func (p Peek) Close() {
 	slices.Reverse(p.br.peekBuf)
 	p.br.buf = append(p.br.buf, p.br.peekBuf...)
 	p.br.peekBuf = p.br.peekBuf[:0]
+	p.br.offset, p.br.pos = p.offset, p.pos
 }
@@ -3,21 +3,38 @@
 
 package hoi4text
 
-import "io"
+import (
+	"io"
+	"iter"
+	"math"
+)
 
 type decoderState struct {
-	r     BufferedReader
-	depth uint
+	r       BufferedReader
+	depth   uint
+	tokens  uint64 // number of tokens consumed
+	path    keyPath
+	lenient *lenientReader
+	limits  *limitReader
+	unknown *unknownReader
 }
 
 func (d *decoderState) ReadToken() (Token, error) {
 	t, err := d.r.ReadToken()
+	if err == nil {
+		d.tokens++
+		d.path.update(t)
+	}
 	d.updateDepth(t.ID())
 	return t, err
 }
 
 func (d *decoderState) SkipToken() (TokenID, error) {
 	id, err := d.r.SkipToken()
+	if err == nil {
+		d.tokens++
+		d.path.skip(id)
+	}
 	d.updateDepth(id)
 	return id, err
 }
@@ -27,7 +44,9 @@ func (d *decoderState) updateDepth(id TokenID) {
 	case TokenOpen:
 		d.depth++
 	case TokenClose:
-		d.depth--
+		if d.depth > 0 {
+			d.depth--
+		}
 	}
 }
 
@@ -35,25 +54,115 @@ type Decoder struct {
 	s              *decoderState
 	minDepth       uint
 	endOfContainer bool
+	op             TokenID
+	err            error
+}
+
+func NewDecoder(r io.Reader, opts ...Option) (*Decoder, error) {
+	tr, err := NewReader(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newDecoder(tr, newOptions(opts)), nil
 }
 
-func NewDecoder(r io.Reader) (*Decoder, error) {
-	tr, err := NewReader(r)
+// NewDecoderBytes is like [NewDecoder], but reads from memory. See
+// [NewReaderBytes].
+func NewDecoderBytes(b []byte, opts ...Option) (*Decoder, error) {
+	tr, err := NewReaderBytes(b, opts...)
 	if err != nil {
 		return nil, err
 	}
-	br := BufferedReader{r: tr}
-	return &Decoder{s: &decoderState{r: br}}, nil
+	return newDecoder(tr, newOptions(opts)), nil
+}
+
+func NewScriptDecoder(r io.Reader, opts ...Option) *Decoder {
+	o := newOptions(opts)
+	return newDecoder(newTextReader(limitInput(r, o, 0, 0), o), o)
+}
+
+// NewDecoderAt returns a [Decoder] that resumes decoding the save read
+// through ra at offset, as reported by [Decoder.Offset] of a decoder whose
+// [Decoder.Depth] was depth. The returned decoder starts at [KindRoot] and
+// ends at the end of the container enclosing offset. Its positions have no
+// line and column.
+func NewDecoderAt(ra io.ReaderAt, offset uint64, depth uint, opts ...Option) (*Decoder, error) {
+	o := newOptions(opts)
+	r, err := newReaderAt(ra, Position{Offset: offset}, math.MaxUint64, o)
+	if err != nil {
+		return nil, err
+	}
+	d := newDecoder(r, o)
+	d.s.depth, d.minDepth = depth, depth
+	d.s.path.levels = make([]pathLevel, depth+1)
+	if d.s.lenient != nil {
+		d.s.lenient.opens = make([]Position, depth)
+	}
+	if d.s.limits != nil {
+		d.s.limits.levels = make([]limitLevel, depth+1)
+	}
+	if d.s.unknown != nil {
+		d.s.unknown.path.levels = make([]pathLevel, depth+1)
+	}
+	return d, nil
+}
+
+func newDecoder(r Reader, opts options) *Decoder {
+	s := &decoderState{}
+	if opts.lenient {
+		s.lenient = &lenientReader{r: r}
+		r = s.lenient
+	}
+	if opts.unknown {
+		s.unknown = &unknownReader{r: r, unknown: make(map[TokenID]UnknownToken)}
+		r = s.unknown
+	}
+	if opts.limits != (Limits{}) {
+		s.limits = newLimitReader(r, opts.limits, 0)
+		r = s.limits
+	}
+	s.r = BufferedReader{r: r}
+	return &Decoder{s: s}
 }
 
 func (d *Decoder) Offset() uint64 {
 	return d.s.r.Offset()
 }
 
+func (d *Decoder) Position() Position {
+	return d.s.r.Position()
+}
+
 func (d *Decoder) Depth() uint {
 	return d.s.depth
 }
 
+// Path returns the object keys and array indices leading to the last token
+// read, such as countries.GER.politics.parties[2].popularity. Keys of
+// skipped tokens are shown as ?.
+func (d *Decoder) Path() string {
+	return string(d.s.path.appendText(nil))
+}
+
+// Diagnostics returns the problems recovered from so far by a decoder
+// created with [WithLenient].
+func (d *Decoder) Diagnostics() []Diagnostic {
+	if d.s.lenient == nil {
+		return nil
+	}
+	return d.s.lenient.diags
+}
+
+// UnknownTokens returns the unresolved ID tokens read so far by a decoder
+// created with [WithUnknownTokens], or nil for other decoders. The map must
+// not be modified.
+func (d *Decoder) UnknownTokens() map[TokenID]UnknownToken {
+	if d.s.unknown == nil {
+		return nil
+	}
+	return d.s.unknown.unknown
+}
+
 func (d *Decoder) ReadToken() (Token, error) {
 	if d.minDepth == 0 {
 		return d.s.ReadToken()
@@ -117,7 +226,12 @@ func (d *Decoder) ReadValue(buf []Token) ([]Token, error) {
 		return nil, err
 	}
 	buf = append(buf, t)
-	if t.ID() != TokenOpen {
+	if t.ID().IsTag() && d.nextIsOpen() {
+		if t, err = d.ReadToken(); err != nil {
+			return nil, err
+		}
+		buf = append(buf, t)
+	} else if t.ID() != TokenOpen {
 		return buf, nil
 	}
 	d = &Decoder{s: d.s, minDepth: d.Depth()}
@@ -128,6 +242,11 @@ func (d *Decoder) SkipValue() error {
 	id, err := d.SkipToken()
 	if err != nil {
 		return err
+	}
+	if id.IsTag() && d.nextIsOpen() {
+		if _, err := d.SkipToken(); err != nil {
+			return err
+		}
 	} else if id != TokenOpen {
 		return nil
 	}
@@ -135,12 +254,19 @@ func (d *Decoder) SkipValue() error {
 	return d.SkipAll()
 }
 
+func (d *Decoder) nextIsOpen() bool {
+	p := d.Peek()
+	defer p.Close()
+	id, err := p.SkipToken()
+	return err == nil && id == TokenOpen
+}
+
 func (d *Decoder) EnterContainer() (*Decoder, error) {
-	id, err := d.SkipToken()
+	t, err := d.ReadToken()
 	if err != nil {
 		return nil, err
-	} else if id != TokenOpen {
-		return nil, &UnexpectedTokenError{id, BeginningOfContainer, d.Offset()}
+	} else if t.ID() != TokenOpen {
+		return nil, &UnexpectedTokenError{t, BeginningOfContainer, d.Position()}
 	}
 	return &Decoder{s: d.s, minDepth: d.Depth()}, nil
 }
@@ -150,16 +276,18 @@ func (d *Decoder) IsEndOfContainer() error {
 		return ErrEndOfContainer
 	}
 	t, err := d.s.r.ReadToken()
-	if t.ID() == TokenClose && d.s.depth == d.minDepth {
+	if t.ID() == TokenClose && d.minDepth == 0 {
+		// The root has no closing brace.
+		d.s.r.unread(t, err)
+		return &UnexpectedTokenError{t, BeginningOfValue, d.s.r.Position()}
+	} else if t.ID() == TokenClose && d.s.depth == d.minDepth {
+		d.s.tokens++
+		d.s.path.update(t)
 		d.s.depth--
 		d.endOfContainer = true
 		return ErrEndOfContainer
 	}
-	d.s.r.buf = append(d.s.r.buf, bufferedToken{
-		token:  t,
-		err:    err,
-		offset: d.s.r.Offset(),
-	})
+	d.s.r.unread(t, err)
 	return err
 }
 
@@ -170,3 +298,89 @@ func (d *Decoder) Peek() Peek {
 func (d *Decoder) PeekKind() (Kind, error) {
 	return d.s.r.PeekKind()
 }
+
+// Entries returns an iterator over the entries of the container or root
+// read by d. It yields the key of each entry and d positioned before the
+// value, with the operator available from [Decoder.Operator]. The loop body
+// must read the whole value or none of it; values that are not read are
+// skipped. Any error stops the iteration and is returned by [Decoder.Err].
+func (d *Decoder) Entries() iter.Seq2[Token, *Decoder] {
+	return func(yield func(Token, *Decoder) bool) {
+		d.err = nil
+		for d.next() {
+			key, err := d.ReadToken()
+			if err != nil {
+				d.err = err
+				return
+			} else if id := key.ID(); id.IsOperator() || id == TokenOpen || id == TokenClose {
+				d.err = &UnexpectedTokenError{key, ObjectKey, d.Position()}
+				return
+			}
+			op, err := d.ReadToken()
+			if d.op = op.ID(); err != nil {
+				d.err = err
+				return
+			} else if !d.op.IsOperator() {
+				d.err = &UnexpectedTokenError{op, KeyValueSeparator, d.Position()}
+				return
+			}
+			tokens := d.s.tokens
+			if !yield(key, d) || !d.skipUnread(tokens) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over the elements of the array read by d.
+// It yields d positioned before each element. The loop body must read the
+// whole element or none of it; elements that are not read are skipped. Any
+// error stops the iteration and is returned by [Decoder.Err].
+func (d *Decoder) Elements() iter.Seq[*Decoder] {
+	return func(yield func(*Decoder) bool) {
+		d.err = nil
+		for d.next() {
+			tokens := d.s.tokens
+			if !yield(d) || !d.skipUnread(tokens) {
+				return
+			}
+		}
+	}
+}
+
+// next reports whether the container has another entry or element. At its
+// end, it records the error that is not part of a normal end.
+func (d *Decoder) next() bool {
+	err := d.IsEndOfContainer()
+	if err == nil {
+		return true
+	} else if (d.minDepth == 0 && err != io.EOF) || (d.minDepth > 0 && err != ErrEndOfContainer) {
+		d.err = err
+	}
+	return false
+}
+
+// skipUnread skips the value after a loop body that did not read any of
+// it, tokens being the number of tokens consumed before the body.
+func (d *Decoder) skipUnread(tokens uint64) bool {
+	if d.s.tokens != tokens {
+		return true
+	} else if err := d.SkipValue(); err != nil {
+		d.err = err
+		return false
+	}
+	return true
+}
+
+// Operator returns the operator of the entry last yielded by
+// [Decoder.Entries].
+func (d *Decoder) Operator() TokenID {
+	return d.op
+}
+
+// Err returns the error that stopped the last iteration of
+// [Decoder.Entries] or [Decoder.Elements], or nil if it reached the end of
+// the container.
+func (d *Decoder) Err() error {
+	return d.err
+}
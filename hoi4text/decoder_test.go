@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestLenient(t *testing.T) {
+	in := "a = { b = 1 } } c = { d = { e = 2 }"
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in), hoi4text.WithLenient())
+	tokens, err := dec.ReadAll(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.String())
+	}
+	if want := "a = { b = 1 } c = { d = { e = 2 } }"; strings.Join(got, " ") != want {
+		t.Fatalf("got %q, want %q", strings.Join(got, " "), want)
+	}
+	want := []hoi4text.Diagnostic{
+		{
+			Problem:  hoi4text.StrayClose,
+			Position: hoi4text.Position{Offset: 14, Line: 1, Column: 15},
+		},
+		{
+			Problem:      hoi4text.MissingClose,
+			Position:     hoi4text.Position{Offset: 35, Line: 1, Column: 36},
+			OpenPosition: hoi4text.Position{Offset: 20, Line: 1, Column: 21},
+		},
+	}
+	if diags := dec.Diagnostics(); len(diags) != len(want) || diags[0] != want[0] || diags[1] != want[1] {
+		t.Fatalf("got %v, want %v", diags, want)
+	}
+}
+
+func TestDecoderAt(t *testing.T) {
+	entry := func(dst []byte, key string, id hoi4text.TokenID) []byte {
+		dst = appendString(dst, hoi4text.TokenUnquoted, key)
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(hoi4text.TokenEqual))
+		return binary.LittleEndian.AppendUint16(dst, uint16(id))
+	}
+	bin := entry([]byte(hoi4text.HeaderBin), "a", hoi4text.TokenOpen)
+	bin = binary.LittleEndian.AppendUint32(entry(bin, "b", hoi4text.TokenI32), 1)
+	bin = entry(bin, "c", hoi4text.TokenOpen)
+	bin = binary.LittleEndian.AppendUint32(entry(bin, "d", hoi4text.TokenI32), 2)
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenClose))
+	binEnd := uint64(len(bin) - hoi4text.HeaderLen)
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenClose))
+	bin = binary.LittleEndian.AppendUint32(entry(bin, "e", hoi4text.TokenI32), 3)
+
+	for _, tt := range []struct {
+		name string
+		in   []byte
+		end  uint64 // offset of the brace closing a
+	}{
+		{"text", []byte(hoi4text.HeaderTxt + "a = { b = 1 c = { d = 2 } } e = 3"), 26},
+		{"binary", bin, binEnd},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dec, err := hoi4text.NewDecoderBytes(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 6 { // a = { b = 1
+				if _, err = dec.ReadToken(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			offset, depth := dec.Offset(), dec.Depth()
+			for _, lenient := range []bool{false, true} {
+				var opts []hoi4text.Option
+				if lenient {
+					opts = append(opts, hoi4text.WithLenient())
+				}
+				dec, err := hoi4text.NewDecoderAt(bytes.NewReader(tt.in), offset, depth, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tokens, err := dec.ReadAll(nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, tok := range tokens {
+					got = append(got, tok.String())
+				}
+				if want := "c = { d = 2 }"; strings.Join(got, " ") != want {
+					t.Fatalf("got %q, want %q", strings.Join(got, " "), want)
+				}
+				if pos := dec.Position(); pos.Offset != tt.end || pos.IsValid() {
+					t.Fatalf("got position %+v, want offset %d without a line", pos, tt.end)
+				}
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	in := `countries = { GER = { politics = { parties = { { popularity = 10 } { popularity = 20 } { popularity = 30 } } } } } ideas = { a b }`
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in))
+	var got []string
+	for {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			break
+		}
+		switch tok.String() {
+		case "30", "b":
+			got = append(got, dec.Path())
+		}
+	}
+	if want := "countries.GER.politics.parties[2].popularity ideas[1]"; strings.Join(got, " ") != want {
+		t.Fatalf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	in := `a = 1 b = { x y z } c < 2 d = { 3 4 }`
+	dec := hoi4text.NewScriptDecoder(strings.NewReader(in))
+	var got []string
+	for key, dec := range dec.Entries() {
+		got = append(got, key.String()+dec.Operator().String())
+		if key.String() != "b" {
+			continue // the value is skipped
+		}
+		inner, err := dec.EnterContainer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for dec := range inner.Elements() {
+			tok, err := dec.ReadToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, tok.String())
+			if tok.String() == "y" {
+				break
+			}
+		}
+		if err := inner.SkipAll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a= b= x y c< d="; strings.Join(got, " ") != want {
+		t.Fatalf("got %q, want %q", strings.Join(got, " "), want)
+	}
+
+	dec = hoi4text.NewScriptDecoder(strings.NewReader(`a = 1 b c`))
+	for range dec.Entries() {
+	}
+	want := `unexpected token unquoted at a key-value separator at 1:9`
+	if err := dec.Err(); err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+type Encoding uint8
+
+const (
+	// Strings are returned as they appear in the source.
+	EncodingNone Encoding = iota
+
+	// A source starting with a UTF-8 byte order mark is read as UTF-8.
+	// Otherwise, each string that is not valid UTF-8 is read as Windows-1252.
+	EncodingAuto
+
+	EncodingUTF8
+	EncodingWindows1252
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingNone:
+		return "none"
+	case EncodingAuto:
+		return "auto"
+	case EncodingUTF8:
+		return "utf-8"
+	case EncodingWindows1252:
+		return "windows-1252"
+	default:
+		return "invalid"
+	}
+}
+
+const bom = "\xef\xbb\xbf"
+
+// decodeString converts b from the source encoding to UTF-8. hasBOM reports
+// whether the source started with a UTF-8 byte order mark. If alias is true
+// and no conversion is needed, the result shares memory with b.
+func decodeString(b []byte, enc Encoding, hasBOM, alias bool) string {
+	switch enc {
+	case EncodingAuto:
+		if hasBOM || utf8.Valid(b) {
+			return toString(b, alias)
+		}
+		return decodeWindows1252(b, alias)
+	case EncodingWindows1252:
+		return decodeWindows1252(b, alias)
+	default:
+		return toString(b, alias)
+	}
+}
+
+func toString(b []byte, alias bool) string {
+	if alias {
+		return unsafe.String(unsafe.SliceData(b), len(b))
+	}
+	return string(b)
+}
+
+func decodeWindows1252(b []byte, alias bool) string {
+	n := 0
+	for _, c := range b {
+		if c < utf8.RuneSelf {
+			n++
+		} else {
+			n += utf8.RuneLen(windows1252[c-0x80])
+		}
+	}
+	if n == len(b) {
+		return toString(b, alias)
+	}
+	dst := make([]byte, 0, n)
+	for _, c := range b {
+		if c < utf8.RuneSelf {
+			dst = append(dst, c)
+		} else {
+			dst = utf8.AppendRune(dst, windows1252[c-0x80])
+		}
+	}
+	return unsafe.String(unsafe.SliceData(dst), len(dst))
+}
+
+// encodeString converts s from UTF-8 to enc.
+func encodeString(s string, enc Encoding) (string, error) {
+	if enc != EncodingWindows1252 {
+		return s, nil
+	}
+	var dst []byte
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			if dst != nil {
+				dst = append(dst, byte(r))
+			}
+			continue
+		}
+		if dst == nil {
+			dst = append(make([]byte, 0, len(s)), s[:i]...)
+		}
+		c, ok := encodeWindows1252(r)
+		if !ok {
+			return "", &UnmappableRuneError{r, enc}
+		}
+		dst = append(dst, c)
+	}
+	if dst == nil {
+		return s, nil
+	}
+	return unsafe.String(unsafe.SliceData(dst), len(dst)), nil
+}
+
+func encodeWindows1252(r rune) (byte, bool) {
+	if r >= 0xa0 && r <= 0xff {
+		return byte(r), true
+	}
+	for i, x := range windows1252[:0x20] {
+		if x == r {
+			return byte(0x80 + i), true //#nosec G115
+		}
+	}
+	return 0, false
+}
+
+// windows1252 maps the bytes 0x80-0xFF to Unicode code points. Undefined
+// bytes map to the C1 control character with the same value.
+var windows1252 = [0x80]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+	0xa0, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+	0xa8, 0xa9, 0xaa, 0xab, 0xac, 0xad, 0xae, 0xaf,
+	0xb0, 0xb1, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6, 0xb7,
+	0xb8, 0xb9, 0xba, 0xbb, 0xbc, 0xbd, 0xbe, 0xbf,
+	0xc0, 0xc1, 0xc2, 0xc3, 0xc4, 0xc5, 0xc6, 0xc7,
+	0xc8, 0xc9, 0xca, 0xcb, 0xcc, 0xcd, 0xce, 0xcf,
+	0xd0, 0xd1, 0xd2, 0xd3, 0xd4, 0xd5, 0xd6, 0xd7,
+	0xd8, 0xd9, 0xda, 0xdb, 0xdc, 0xdd, 0xde, 0xdf,
+	0xe0, 0xe1, 0xe2, 0xe3, 0xe4, 0xe5, 0xe6, 0xe7,
+	0xe8, 0xe9, 0xea, 0xeb, 0xec, 0xed, 0xee, 0xef,
+	0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7,
+	0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff,
+}
@@ -16,19 +16,79 @@
 )
 
 type UnexpectedTokenError struct {
-	TokenID TokenID
-	Where   Where
-	Offset  uint64
+	Token    Token
+	Where    Where
+	Position Position
 }
 
 func (e *UnexpectedTokenError) Error() string {
 	var dst []byte
 	dst = append(dst, "unexpected token "...)
-	dst = append(dst, e.TokenID.String()...)
+	dst = append(dst, e.Token.Name()...)
 	dst = append(dst, " at "...)
 	dst = append(dst, e.Where...)
-	dst = append(dst, " at offset "...)
-	dst = strconv.AppendUint(dst, e.Offset, 10)
+	dst = append(dst, " at "...)
+	dst = e.Position.AppendText(dst)
+	return string(dst)
+}
+
+type UnmappableRuneError struct {
+	Rune     rune
+	Encoding Encoding
+}
+
+func (e *UnmappableRuneError) Error() string {
+	var dst []byte
+	dst = append(dst, "rune "...)
+	dst = strconv.AppendQuoteRune(dst, e.Rune)
+	dst = append(dst, " cannot be encoded in "...)
+	dst = append(dst, e.Encoding.String()...)
+	return string(dst)
+}
+
+type UndefinedVariableError struct {
+	Name     string
+	Position Position
+}
+
+func (e *UndefinedVariableError) Error() string {
+	var dst []byte
+	dst = append(dst, "undefined variable @"...)
+	dst = append(dst, e.Name...)
+	dst = append(dst, " at "...)
+	dst = e.Position.AppendText(dst)
+	return string(dst)
+}
+
+type InvalidExpressionError struct {
+	Expr     string
+	Position Position
+}
+
+func (e *InvalidExpressionError) Error() string {
+	var dst []byte
+	dst = append(dst, "invalid expression "...)
+	dst = strconv.AppendQuote(dst, e.Expr)
+	dst = append(dst, " at "...)
+	dst = e.Position.AppendText(dst)
+	return string(dst)
+}
+
+type LimitExceededError struct {
+	// Limit is the name of the exceeded [Limits] field.
+	Limit    string
+	Max      uint64
+	Position Position
+}
+
+func (e *LimitExceededError) Error() string {
+	var dst []byte
+	dst = append(dst, "limit "...)
+	dst = append(dst, e.Limit...)
+	dst = append(dst, " of "...)
+	dst = strconv.AppendUint(dst, e.Max, 10)
+	dst = append(dst, " exceeded at "...)
+	dst = e.Position.AppendText(dst)
 	return string(dst)
 }
 
@@ -38,4 +98,7 @@ func (e *UnexpectedTokenError) Error() string {
 	BeginningOfContainer Where = "the beginning of a container"
 	BeginningOfValue     Where = "the beginning of a value"
 	FirstTokenOfValue    Where = "the first token of a value"
+	ObjectKey            Where = "an object key"
+	KeyValueSeparator    Where = "a key-value separator"
+	BinaryInput          Where = "binary input"
 )
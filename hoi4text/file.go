@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+)
+
+// File is a save file opened for decoding. Where supported, the file is
+// memory-mapped and read without copying. Otherwise, it is read through a
+// buffer.
+//
+// Strings of tokens read from a mapped File share memory with the mapping,
+// so the file must not be modified while they are in use. The mapping is
+// only released once the File and its readers are garbage collected, so the
+// strings stay valid after [File.Close] for as long as the File is
+// reachable. Use [WithCopyStrings] for tokens that must outlive the File.
+type File struct {
+	f      *os.File
+	data   []byte // mapped contents, nil if the file is not mapped
+	opts   []Option
+	closed bool
+}
+
+func OpenFile(name string, opts ...Option) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := mmap(f)
+	if err != nil {
+		data = nil
+	}
+	file := &File{f: f, data: data}
+	file.opts = slices.Concat(opts, []Option{WithFilename(name), withOwner(file)})
+	if data != nil {
+		runtime.AddCleanup(file, func(data []byte) { _ = munmap(data) }, data)
+	}
+	return file, nil
+}
+
+// IsMapped reports whether the file is memory-mapped.
+func (f *File) IsMapped() bool {
+	return f.data != nil
+}
+
+// NewReader returns a [Reader] positioned at the start of the file.
+func (f *File) NewReader() (Reader, error) {
+	if f.closed {
+		return nil, os.ErrClosed
+	} else if f.data != nil {
+		return NewReaderBytes(f.data, f.opts...)
+	}
+	if _, err := f.f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return NewReader(bufio.NewReader(f.f), f.opts...)
+}
+
+// NewDecoder returns a [Decoder] positioned at the start of the file.
+func (f *File) NewDecoder() (*Decoder, error) {
+	r, err := f.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	return newDecoder(r, newOptions(f.opts)), nil
+}
+
+// Close closes the file. The mapping is left to the garbage collector, so
+// tokens already read stay valid.
+func (f *File) Close() error {
+	f.closed = true
+	return f.f.Close()
+}
+
+func withOwner(owner any) Option {
+	return func(o *options) {
+		o.owner = owner
+	}
+}
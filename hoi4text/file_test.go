@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestFile(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []hoi4text.Option
+	}{
+		{"alias", nil},
+		{"copy", []hoi4text.Option{hoi4text.WithCopyStrings()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.hoi4")
+			in := appendString([]byte(hoi4text.HeaderBin), hoi4text.TokenQuoted, "FRA")
+			if err := os.WriteFile(path, in, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := hoi4text.OpenFile(path, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := f.IsMapped(), runtime.GOOS == "linux"; got != want {
+				t.Fatalf("IsMapped() = %v, want %v", got, want)
+			}
+			dec, err := f.NewDecoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tok, err := dec.ReadToken()
+			if err != nil {
+				t.Fatal(err)
+			} else if tok.Quoted() != "FRA" {
+				t.Fatalf("got %v, want %q", tok, "FRA")
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+			runtime.GC()
+			if tok.Quoted() != "FRA" {
+				t.Fatalf("got %v after Close, want %q", tok, "FRA")
+			}
+			if _, err := f.NewDecoder(); err == nil {
+				t.Fatal("NewDecoder succeeded after Close")
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Index maps the top-level and second-level keys of a save to the byte
+// ranges of their entries, so that single entries can be decoded without
+// reading the whole save.
+type Index struct {
+	Entries []IndexEntry
+}
+
+type IndexEntry struct {
+	Key string
+	// Position of the key.
+	Position Position
+	// Length of the entry in bytes, from the key to the end of its value.
+	Length uint64
+	// Entries of the value, only set for top-level entries.
+	Entries []IndexEntry
+}
+
+// Find returns the first entry with the given key.
+func (ix *Index) Find(key string) (IndexEntry, bool) {
+	return find(ix.Entries, key)
+}
+
+// Find returns the first second-level entry with the given key.
+func (e IndexEntry) Find(key string) (IndexEntry, bool) {
+	return find(e.Entries, key)
+}
+
+func find(entries []IndexEntry, key string) (IndexEntry, bool) {
+	for _, e := range entries {
+		if e.Key == key {
+			return e, true
+		}
+	}
+	return IndexEntry{}, false
+}
+
+// NewIndex builds an [Index] of the save read from r. Values below the
+// second level are skipped without being read.
+func NewIndex(r io.Reader, opts ...Option) (*Index, error) {
+	tr, err := NewReader(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := indexEntries(&BufferedReader{r: tr}, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Index{entries}, nil
+}
+
+// indexEntries indexes the entries up to the end of the input, or up to the
+// end of the current container if topLevel is false.
+func indexEntries(br *BufferedReader, topLevel bool) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	for {
+		t, err := br.ReadToken()
+		if err == io.EOF && topLevel {
+			return entries, nil
+		} else if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		} else if t.ID() == TokenClose && !topLevel {
+			return entries, nil
+		}
+		start := br.Position()
+		if !nextIsOperator(br) {
+			// An array element.
+			if err = skipRest(br, t.ID()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err = br.SkipToken(); err != nil {
+			return nil, err
+		}
+		e := IndexEntry{Key: indexKey(t), Position: start}
+		id, err := br.SkipToken()
+		if err != nil {
+			return nil, err
+		}
+		if id.IsTag() && nextIsOpen(br) {
+			if id, err = br.SkipToken(); err != nil {
+				return nil, err
+			}
+		}
+		if id == TokenOpen && topLevel {
+			e.Entries, err = indexEntries(br, false)
+		} else {
+			err = skipRest(br, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.Length = br.Offset() - start.Offset
+		entries = append(entries, e)
+	}
+}
+
+func indexKey(t Token) string {
+	if t.ID() == TokenQuoted {
+		return t.Quoted()
+	}
+	return t.String()
+}
+
+func nextIsOperator(br *BufferedReader) bool {
+	p := br.Peek()
+	defer p.Close()
+	id, err := p.SkipToken()
+	return err == nil && id.IsOperator()
+}
+
+func nextIsOpen(br *BufferedReader) bool {
+	p := br.Peek()
+	defer p.Close()
+	id, err := p.SkipToken()
+	return err == nil && id == TokenOpen
+}
+
+// skipRest skips the rest of a value whose first token was id.
+func skipRest(br *BufferedReader, id TokenID) error {
+	if id != TokenOpen {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		id, err := br.SkipToken()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		switch id {
+		case TokenOpen:
+			depth++
+		case TokenClose:
+			depth--
+		}
+	}
+	return nil
+}
+
+// NewDecoder returns a [Decoder] that reads only the entry e of the save
+// read through ra. The decoder starts at [KindRoot], so the entry can be
+// unmarshaled into a struct with a field for its key.
+func (e IndexEntry) NewDecoder(ra io.ReaderAt, opts ...Option) (*Decoder, error) {
+	o := newOptions(opts)
+	r, err := newReaderAt(ra, e.Position, e.Length, o)
+	if err != nil {
+		return nil, err
+	}
+	return newDecoder(r, o), nil
+}
+
+// newReaderAt returns a [Reader] for the n bytes at pos of the save read
+// through ra.
+func newReaderAt(ra io.ReaderAt, pos Position, n uint64, opts options) (Reader, error) {
+	var header [HeaderLen + len(bom)]byte
+	if m, err := ra.ReadAt(header[:], 0); m < HeaderLen {
+		if err == io.EOF {
+			return nil, ErrUnknownHeader
+		}
+		return nil, err
+	}
+	start := uint64(HeaderLen) + pos.Offset
+	n = min(n, math.MaxInt64)
+	sr := io.NewSectionReader(ra, int64(start), int64(n)) //#nosec G115
+	r := bufio.NewReader(limitInput(sr, opts, start, uint64(HeaderLen)))
+	pos.File = opts.filename
+	switch string(header[:HeaderLen]) {
+	case HeaderBin:
+		if opts.tables != nil {
+			opts.resolver = selectTableAt(ra, opts)
+		}
+		return &BinaryReader{r: r, offset: pos.Offset, start: pos.Offset, opts: opts}, nil
+	case HeaderTxt:
+		tr := newTextReader(r, opts)
+		tr.next, tr.start = pos, pos
+		tr.bom = string(header[HeaderLen:]) == bom
+		return tr, nil
+	default:
+		return nil, ErrUnknownHeader
+	}
+}
+
+var ErrInvalidIndex = errors.New("invalid index")
+
+const indexVersion = 1
+
+// WriteTo writes ix in a compact binary format that can be read back with
+// [ReadIndex].
+func (ix *Index) WriteTo(w io.Writer) (int64, error) {
+	buf := []byte{indexVersion}
+	buf = appendIndexEntries(buf, ix.Entries)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+func appendIndexEntries(dst []byte, entries []IndexEntry) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(entries)))
+	for _, e := range entries {
+		dst = binary.AppendUvarint(dst, uint64(len(e.Key)))
+		dst = append(dst, e.Key...)
+		dst = binary.AppendUvarint(dst, e.Position.Offset)
+		dst = binary.AppendUvarint(dst, e.Position.Line)
+		dst = binary.AppendUvarint(dst, e.Position.Column)
+		dst = binary.AppendUvarint(dst, e.Length)
+		dst = appendIndexEntries(dst, e.Entries)
+	}
+	return dst
+}
+
+func ReadIndex(r io.Reader) (*Index, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != indexVersion {
+		return nil, ErrInvalidIndex
+	}
+	d := indexDecoder{data[1:]}
+	entries := d.entries(2)
+	if d.data == nil || len(d.data) != 0 {
+		return nil, ErrInvalidIndex
+	}
+	return &Index{entries}, nil
+}
+
+// indexDecoder reads the format written by [Index.WriteTo]. On malformed
+// input, data is set to nil.
+type indexDecoder struct {
+	data []byte
+}
+
+func (d *indexDecoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.data = nil
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+func (d *indexDecoder) entries(levels int) []IndexEntry {
+	n := d.uvarint()
+	if n == 0 {
+		return nil
+	} else if levels == 0 || n > uint64(len(d.data)) {
+		d.data = nil
+		return nil
+	}
+	entries := make([]IndexEntry, 0, n)
+	for range n {
+		var e IndexEntry
+		if length := d.uvarint(); length <= uint64(len(d.data)) {
+			e.Key = string(d.data[:length])
+			d.data = d.data[length:]
+		} else {
+			d.data = nil
+		}
+		e.Position.Offset = d.uvarint()
+		e.Position.Line = d.uvarint()
+		e.Position.Column = d.uvarint()
+		e.Length = d.uvarint()
+		e.Entries = d.entries(levels - 1)
+		if d.data == nil {
+			return nil
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestIndex(t *testing.T) {
+	text := hoi4text.HeaderTxt + "\ndate=\"1936.1.1\"\ncountries={\n\tGER={ capital=64 ideas={ a b } }\n\tFRA={ capital=16 }\n}\ncolor=rgb { 1 2 3 }\nlist={ 1 2 3 }\n"
+	bin := appendString([]byte(hoi4text.HeaderBin), hoi4text.TokenQuoted, "countries")
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenEqual))
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenOpen))
+	bin = appendString(bin, hoi4text.TokenQuoted, "GER")
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenEqual))
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenOpen))
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenClose))
+	bin = binary.LittleEndian.AppendUint16(bin, uint16(hoi4text.TokenClose))
+
+	for _, tt := range []struct {
+		name string
+		in   []byte
+		keys []string
+		line uint64
+		want string
+	}{
+		{"text", []byte(text), []string{"date", "countries", "color", "list"}, 4, "GER = { capital = 64 ideas = { a b } }"},
+		{"binary", bin, []string{"countries"}, 0, `"GER" = { }`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ix, err := hoi4text.NewIndex(bytes.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, e := range ix.Entries {
+				keys = append(keys, e.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Fatalf("got keys %q, want %q", keys, tt.keys)
+			}
+
+			var buf bytes.Buffer
+			if _, err = ix.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			read, err := hoi4text.ReadIndex(&buf)
+			if err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(read, ix) {
+				t.Fatalf("got %+v, want %+v", read, ix)
+			}
+
+			countries, _ := ix.Find("countries")
+			ger, ok := countries.Find("GER")
+			if !ok {
+				t.Fatal("GER not indexed")
+			}
+			dec, err := ger.NewDecoder(bytes.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens, err := dec.ReadAll(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tok := range tokens {
+				got = append(got, tok.String())
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Fatalf("got %q, want %q", strings.Join(got, " "), tt.want)
+			}
+			if ger.Position.Line != tt.line {
+				t.Fatalf("got line %d, want %d", ger.Position.Line, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import "io"
+
+type Diagnostic struct {
+	Problem Problem
+	// Position at which the problem was detected.
+	Position Position
+	// Position of the unclosed [TokenOpen], only set for [MissingClose].
+	OpenPosition Position
+}
+
+func (d Diagnostic) String() string {
+	var dst []byte
+	dst = append(dst, d.Problem...)
+	dst = append(dst, " at "...)
+	dst = d.Position.AppendText(dst)
+	if d.Problem == MissingClose {
+		dst = append(dst, " for the container opened at "...)
+		dst = d.OpenPosition.AppendText(dst)
+	}
+	return string(dst)
+}
+
+type Problem string
+
+const (
+	StrayClose   Problem = "stray closing brace"
+	MissingClose Problem = "missing closing brace"
+)
+
+// lenientReader drops unmatched [TokenClose] tokens and closes containers
+// left open at EOF, recording a [Diagnostic] for each.
+type lenientReader struct {
+	r     Reader
+	opens []Position
+	diags []Diagnostic
+}
+
+func (r *lenientReader) Offset() uint64 {
+	return r.r.Offset()
+}
+
+func (r *lenientReader) Position() Position {
+	return position(r.r)
+}
+
+func (r *lenientReader) ReadToken() (Token, error) {
+	for {
+		t, err := r.r.ReadToken()
+		id, drop, err := r.check(t.ID(), err)
+		switch {
+		case drop:
+			continue
+		case err != nil:
+			return t, err
+		case id != t.ID():
+			return ID(id), nil
+		default:
+			return t, nil
+		}
+	}
+}
+
+func (r *lenientReader) SkipToken() (TokenID, error) {
+	for {
+		id, err := SkipToken(r.r)
+		if id, drop, err := r.check(id, err); !drop {
+			return id, err
+		}
+	}
+}
+
+// check updates the open containers for a token that was just read and
+// reports whether the token must be dropped. At EOF, it returns a
+// [TokenClose] for each container that is still open.
+func (r *lenientReader) check(id TokenID, err error) (TokenID, bool, error) {
+	switch {
+	case err == io.EOF && len(r.opens) > 0:
+		r.diags = append(r.diags, Diagnostic{
+			Problem:      MissingClose,
+			Position:     r.Position(),
+			OpenPosition: r.opens[len(r.opens)-1],
+		})
+		r.opens = r.opens[:len(r.opens)-1]
+		return TokenClose, false, nil
+	case err != nil:
+		return id, false, err
+	case id == TokenOpen:
+		r.opens = append(r.opens, r.Position())
+	case id == TokenClose:
+		if len(r.opens) == 0 {
+			r.diags = append(r.diags, Diagnostic{
+				Problem:  StrayClose,
+				Position: r.Position(),
+			})
+			return id, true, nil
+		}
+		r.opens = r.opens[:len(r.opens)-1]
+	}
+	return id, false, nil
+}
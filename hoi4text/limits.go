@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import "io"
+
+// Limits bounds the resources used to decode untrusted input. A zero field
+// means no limit.
+type Limits struct {
+	// MaxDepth is the maximum nesting depth of containers.
+	MaxDepth uint
+	// MaxTokens is the maximum number of tokens read or skipped.
+	MaxTokens uint64
+	// MaxStringBytes is the maximum total length of all strings.
+	MaxStringBytes uint64
+	// MaxContainerElements is the maximum number of entries or elements
+	// directly inside a container, including the root.
+	MaxContainerElements uint64
+	// MaxInputSize is the maximum size of the input in bytes, including the
+	// header.
+	MaxInputSize uint64
+}
+
+// WithLimits makes readers and decoders fail with a [*LimitExceededError]
+// once the input exceeds one of the limits. MaxInputSize is enforced by
+// readers, the other limits only by a [Decoder].
+func WithLimits(l Limits) Option {
+	return func(o *options) {
+		o.limits = l
+	}
+}
+
+// inputLimiter fails reads past the MaxInputSize limit.
+type inputLimiter struct {
+	r   io.Reader
+	n   uint64 // bytes left
+	err error
+}
+
+// limitInput wraps r to enforce the MaxInputSize limit. consumed is the
+// number of bytes of the input that precede r, and header the length of its
+// header, which is 0 for scripts.
+func limitInput(r io.Reader, opts options, consumed, header uint64) io.Reader {
+	limit := opts.limits.MaxInputSize
+	if limit == 0 {
+		return r
+	}
+	return &inputLimiter{r, max(limit, consumed) - consumed, inputSizeError(opts, header)}
+}
+
+// inputSizeError returns the error for input past the MaxInputSize limit.
+// Its offset, like those of tokens, does not count the header.
+func inputSizeError(opts options, header uint64) error {
+	limit := opts.limits.MaxInputSize
+	return &LimitExceededError{"MaxInputSize", limit, Position{
+		File:   opts.filename,
+		Offset: max(limit, header) - header,
+	}}
+}
+
+func (l *inputLimiter) Read(p []byte) (int, error) {
+	if l.n == 0 {
+		var b [1]byte
+		if _, err := io.ReadAtLeast(l.r, b[:], 1); err != nil {
+			return 0, err
+		}
+		return 0, l.err
+	}
+	if uint64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= uint64(n) //#nosec G115
+	return n, err
+}
+
+// limitReader enforces the limits checked by a [Decoder].
+type limitReader struct {
+	r       Reader
+	limits  Limits
+	tokens  uint64
+	strings uint64
+	// levels holds the root and each open container.
+	levels []limitLevel
+}
+
+type limitLevel struct {
+	elements uint64
+	prev     TokenID
+}
+
+func newLimitReader(r Reader, limits Limits, depth uint) *limitReader {
+	return &limitReader{r: r, limits: limits, levels: make([]limitLevel, depth+1)}
+}
+
+func (r *limitReader) Offset() uint64 {
+	return r.r.Offset()
+}
+
+func (r *limitReader) Position() Position {
+	return position(r.r)
+}
+
+func (r *limitReader) ReadToken() (Token, error) {
+	t, err := r.r.ReadToken()
+	if err != nil {
+		return t, err
+	}
+	var n int
+	switch t.ID() {
+	case TokenQuoted, TokenUnquoted:
+		n = len(t.getString())
+	}
+	return t, r.check(t.ID(), n)
+}
+
+func (r *limitReader) SkipToken() (TokenID, error) {
+	if r.limits.MaxStringBytes > 0 {
+		// The length of a skipped string is unknown.
+		t, err := r.ReadToken()
+		return t.ID(), err
+	}
+	id, err := SkipToken(r.r)
+	if err != nil {
+		return id, err
+	}
+	return id, r.check(id, 0)
+}
+
+func (r *limitReader) check(id TokenID, n int) error {
+	l := &r.limits
+	r.tokens++
+	if l.MaxTokens > 0 && r.tokens > l.MaxTokens {
+		return r.exceeded("MaxTokens", l.MaxTokens)
+	}
+	r.strings += uint64(n) //#nosec G115
+	if l.MaxStringBytes > 0 && r.strings > l.MaxStringBytes {
+		return r.exceeded("MaxStringBytes", l.MaxStringBytes)
+	}
+	if id.IsTrivia() {
+		return nil
+	}
+	lv := &r.levels[len(r.levels)-1]
+	// Values and the container that follows a tag are part of the element
+	// that precedes them.
+	if !id.IsOperator() && id != TokenClose && !lv.prev.IsOperator() && (id != TokenOpen || !lv.prev.IsTag()) {
+		lv.elements++
+		if l.MaxContainerElements > 0 && lv.elements > l.MaxContainerElements {
+			return r.exceeded("MaxContainerElements", l.MaxContainerElements)
+		}
+	}
+	lv.prev = id
+	switch id {
+	case TokenOpen:
+		r.levels = append(r.levels, limitLevel{})
+		if depth := uint(len(r.levels) - 1); l.MaxDepth > 0 && depth > l.MaxDepth {
+			return r.exceeded("MaxDepth", uint64(l.MaxDepth))
+		}
+	case TokenClose:
+		if len(r.levels) > 1 {
+			r.levels = r.levels[:len(r.levels)-1]
+		}
+	}
+	return nil
+}
+
+func (r *limitReader) exceeded(limit string, max uint64) error {
+	return &LimitExceededError{limit, max, r.Position()}
+}
@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package hoi4text
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func mmap(f *os.File) ([]byte, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size <= 0 || int64(int(size)) != size {
+		return nil, errors.ErrUnsupported
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE) //#nosec G115
+}
+
+func munmap(b []byte) error {
+	return syscall.Munmap(b)
+}
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package hoi4text
+
+import (
+	"errors"
+	"os"
+)
+
+func mmap(*os.File) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func munmap([]byte) error {
+	return nil
+}
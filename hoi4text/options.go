@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+type Option func(*options)
+
+type options struct {
+	trivia    bool
+	encoding  Encoding
+	variables bool
+	lenient   bool
+	filename  string
+	copy      bool
+	limits    Limits
+	resolver  *resolverRef
+	unknown   bool
+	tables    *TokenTables
+	owner     any // keeps the memory that strings alias reachable
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTrivia makes a [TextReader] emit comments and whitespace as
+// [TokenComment] and [TokenWhitespace] tokens, so that the source can be
+// re-emitted with a [TextWriter].
+func WithTrivia() Option {
+	return func(o *options) {
+		o.trivia = true
+	}
+}
+
+// WithEncoding sets the encoding of strings in the source. Readers transcode
+// [TokenQuoted] and [TokenUnquoted] payloads from it to UTF-8, and a
+// [TextWriter] transcodes them from UTF-8 to it.
+func WithEncoding(enc Encoding) Option {
+	return func(o *options) {
+		o.encoding = enc
+	}
+}
+
+// WithVariables makes a [TextReader] resolve scripted variables. Definitions
+// such as @x = 5 are consumed, and references such as @x or @[x * 2] are
+// replaced by the value or the result of the expression. With [WithTrivia],
+// comments and whitespace inside a definition are dropped with it.
+func WithVariables() Option {
+	return func(o *options) {
+		o.variables = true
+	}
+}
+
+// WithLenient makes a [Decoder] recover from unbalanced braces instead of
+// failing. Unmatched closing braces are dropped and containers left open at
+// EOF are closed. Each recovery is reported by [Decoder.Diagnostics].
+func WithLenient() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// WithFilename sets the file name reported in each [Position].
+func WithFilename(name string) Option {
+	return func(o *options) {
+		o.filename = name
+	}
+}
+
+// WithCopyStrings makes a reader created by [NewReaderBytes] copy strings
+// instead of returning strings that share memory with its input. Use it
+// when the input will be modified while the tokens are still in use.
+func WithCopyStrings() Option {
+	return func(o *options) {
+		o.copy = true
+	}
+}
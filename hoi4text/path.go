@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import "strconv"
+
+// keyPath tracks the keys and array indices leading to the last token read.
+type keyPath struct {
+	levels []pathLevel // the root and each open container
+	// prev is the last token, or the zero token if it was skipped.
+	prev Token
+}
+
+type pathLevel struct {
+	key      Token
+	hasKey   bool
+	elements uint64
+	object   bool
+	prev     TokenID
+}
+
+func (p *keyPath) update(t Token) {
+	if p.advance(t.id) {
+		p.prev = t
+	}
+}
+
+func (p *keyPath) skip(id TokenID) {
+	if p.advance(id) {
+		p.prev = Token{}
+	}
+}
+
+func (p *keyPath) advance(id TokenID) bool {
+	if id.IsTrivia() {
+		return false
+	}
+	if len(p.levels) == 0 {
+		p.levels = append(p.levels, pathLevel{})
+	}
+	lv := &p.levels[len(p.levels)-1]
+	switch {
+	case id.IsOperator():
+		lv.key, lv.hasKey, lv.object = p.prev, true, true
+	case id != TokenClose && !lv.prev.IsOperator() && (id != TokenOpen || !lv.prev.IsTag()):
+		// A new entry or element.
+		lv.key, lv.hasKey = Token{}, false
+		lv.elements++
+	}
+	lv.prev = id
+	switch {
+	case id == TokenOpen:
+		p.levels = append(p.levels, pathLevel{})
+	case id == TokenClose && len(p.levels) > 1:
+		p.levels = p.levels[:len(p.levels)-1]
+	}
+	return true
+}
+
+// keys returns the keys leading to the last token read.
+func (p *keyPath) keys() []string {
+	var keys []string
+	for _, lv := range p.levels {
+		if lv.hasKey {
+			keys = append(keys, pathKey(lv.key))
+		}
+	}
+	return keys
+}
+
+// appendText appends the path in a form such as a.b[2].c.
+func (p *keyPath) appendText(dst []byte) []byte {
+	start := len(dst)
+	for i, lv := range p.levels {
+		switch {
+		case lv.hasKey:
+			if len(dst) > start {
+				dst = append(dst, '.')
+			}
+			dst = append(dst, pathKey(lv.key)...)
+		case i > 0 && !lv.object && lv.elements > 0:
+			dst = append(dst, '[')
+			dst = strconv.AppendUint(dst, lv.elements-1, 10)
+			dst = append(dst, ']')
+		}
+	}
+	return dst
+}
+
+func pathKey(t Token) string {
+	switch t.id {
+	case TokenInvalid:
+		return "?"
+	case TokenQuoted:
+		return t.getString()
+	}
+	return t.String()
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import "strconv"
+
+// Position identifies a place in the source. Offsets are counted from the
+// end of the header, lines and columns from the start of the file. Line
+// and Column are zero for binary sources.
+type Position struct {
+	File   string
+	Offset uint64
+	Line   uint64
+	Column uint64
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	return string(p.AppendText(nil))
+}
+
+func (p Position) AppendText(dst []byte) []byte {
+	if p.File != "" {
+		dst = append(dst, p.File...)
+		dst = append(dst, ':')
+	}
+	if !p.IsValid() {
+		dst = append(dst, "offset "...)
+		return strconv.AppendUint(dst, p.Offset, 10)
+	}
+	dst = strconv.AppendUint(dst, p.Line, 10)
+	dst = append(dst, ':')
+	return strconv.AppendUint(dst, p.Column, 10)
+}
+
+// advance returns the position after the text s that starts at p.
+func (p Position) advance(s string) Position {
+	for i := range len(s) {
+		p.Offset++
+		if !p.IsValid() {
+			continue
+		} else if s[i] == '\n' {
+			p.Line++
+			p.Column = 1
+		} else {
+			p.Column++
+		}
+	}
+	return p
+}
+
+// Positioner is implemented by readers that can report the [Position] of
+// the first byte of the last token read, or of the end of the input once it
+// is reached.
+type Positioner interface {
+	Position() Position
+}
+
+func position(r Reader) Position {
+	if p, ok := r.(Positioner); ok {
+		return p.Position()
+	}
+	return Position{Offset: r.Offset()}
+}
@@ -3,7 +3,10 @@
 
 package hoi4text
 
-import "io"
+import (
+	"bytes"
+	"io"
+)
 
 type Reader interface {
 	ReadToken() (Token, error)
@@ -22,7 +25,9 @@ type Skipper interface {
 
 var _ [len(HeaderTxt)]int = [len(HeaderBin)]int{}
 
-func NewReader(r io.Reader) (Reader, error) {
+func NewReader(r io.Reader, opts ...Option) (Reader, error) {
+	o := newOptions(opts)
+	r = limitInput(r, o, 0, uint64(HeaderLen))
 	buf, err := read(r, HeaderLen, nil)
 	if err == io.ErrUnexpectedEOF {
 		return nil, ErrUnknownHeader
@@ -31,14 +36,45 @@ func NewReader(r io.Reader) (Reader, error) {
 	}
 	switch string(buf) {
 	case HeaderBin:
-		return &BinaryReader{r: r, buf: buf}, nil
+		return withTokenTables(&BinaryReader{r: r, buf: buf, opts: o}), nil
 	case HeaderTxt:
-		return nil, ErrUnimplemented
+		tr := newTextReader(r, o)
+		tr.next.Column += uint64(HeaderLen)
+		tr.start = tr.next
+		return tr, nil
 	default:
 		return nil, ErrUnknownHeader
 	}
 }
 
+// NewReaderBytes is like [NewReader], but reads from memory. The strings of
+// binary tokens share memory with b unless [WithCopyStrings] is given, so b
+// must not be modified while they are in use.
+func NewReaderBytes(b []byte, opts ...Option) (Reader, error) {
+	if len(b) < HeaderLen {
+		return nil, ErrUnknownHeader
+	}
+	o := newOptions(opts)
+	if limit := o.limits.MaxInputSize; limit > 0 && uint64(len(b)) > limit {
+		return nil, inputSizeError(o, uint64(HeaderLen))
+	}
+	switch string(b[:HeaderLen]) {
+	case HeaderBin:
+		return withTokenTables(&BinaryReader{data: b[HeaderLen:], mem: true, opts: o}), nil
+	case HeaderTxt:
+		return NewReader(bytes.NewReader(b), opts...)
+	default:
+		return nil, ErrUnknownHeader
+	}
+}
+
+// NewScriptReader returns a [TextReader] for headerless text, such as the
+// game and mod files under common/, history/ and events/.
+func NewScriptReader(r io.Reader, opts ...Option) *TextReader {
+	o := newOptions(opts)
+	return newTextReader(limitInput(r, o, 0, 0), o)
+}
+
 func SkipToken(r Reader) (TokenID, error) {
 	if s, ok := r.(Skipper); ok {
 		return s.SkipToken()
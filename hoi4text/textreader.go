@@ -0,0 +1,385 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"bufio"
+	"io"
+)
+
+type TextReader struct {
+	r     *bufio.Reader
+	buf   []byte
+	raw   []byte   // source text of the last quoted string, with trivia
+	next  Position // position of the next unread byte
+	last  Position // position of the last read byte
+	start Position // position of the last token
+	opts  options
+	bom   bool
+	vars  map[string]Token
+}
+
+func newTextReader(r io.Reader, opts options) *TextReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	next := Position{File: opts.filename, Line: 1, Column: 1}
+	return &TextReader{r: br, next: next, start: next, opts: opts}
+}
+
+func (r *TextReader) Offset() uint64 {
+	return r.next.Offset
+}
+
+func (r *TextReader) Position() Position {
+	return r.start
+}
+
+func (r *TextReader) ReadToken() (Token, error) {
+	for {
+		var t Token
+		id, b, err := r.scan()
+		if err != nil {
+			return t, err
+		}
+		if r.opts.variables && isVariable(id, b) {
+			t, ok, err := r.variable(b)
+			if err != nil || ok {
+				return t, err
+			}
+			continue
+		}
+		switch id {
+		case TokenQuoted:
+			t = Quoted(decodeString(b, r.opts.encoding, r.bom, false))
+			if r.opts.trivia {
+				// Keep escapes that the writer would not reproduce.
+				raw := decodeString(r.raw, r.opts.encoding, r.bom, false)
+				if q := appendQuoted(nil, t.Quoted()); string(q[1:len(q)-1]) != raw {
+					t = quotedRaw(t.Quoted(), raw)
+				}
+			}
+		case TokenUnquoted:
+			t = Unquoted(decodeString(b, r.opts.encoding, r.bom, false))
+		case TokenComment:
+			t = Comment(string(b))
+		case TokenWhitespace:
+			t = Whitespace(string(b))
+		default:
+			t = ID(id)
+		}
+		return t, nil
+	}
+}
+
+func (r *TextReader) SkipToken() (TokenID, error) {
+	if r.opts.variables {
+		t, err := r.ReadToken()
+		return t.ID(), err
+	}
+	id, _, err := r.scan()
+	if err != nil {
+		return TokenInvalid, err
+	}
+	return id, nil
+}
+
+// scan reads the next token. The returned slice holds the contents of
+// a string or trivia token and is only valid until the next call.
+func (r *TextReader) scan() (TokenID, []byte, error) {
+	if r.next.Offset == 0 && !r.bom {
+		if b, _ := r.r.Peek(len(bom)); string(b) == bom {
+			r.bom = true
+			r.start = r.next
+			_, _ = r.r.Discard(len(bom))
+			r.next.Offset += uint64(len(bom))
+			if r.opts.trivia {
+				return TokenWhitespace, append(r.buf[:0], bom...), nil
+			}
+		}
+	}
+	var c byte
+	var err error
+	if r.opts.trivia {
+		c, err = r.readByte()
+	} else {
+		c, err = r.skipSpace()
+	}
+	if err != nil {
+		r.start = r.next
+		return TokenInvalid, nil, err
+	}
+	r.start = r.last
+	if isSpace(c) {
+		b, err := r.readWhitespace(c)
+		if err != nil {
+			return TokenInvalid, nil, err
+		}
+		return TokenWhitespace, b, nil
+	}
+	switch c {
+	case '#':
+		b, err := r.readComment()
+		if err != nil {
+			return TokenInvalid, nil, err
+		}
+		return TokenComment, b, nil
+	case '{':
+		return TokenOpen, nil, nil
+	case '}':
+		return TokenClose, nil, nil
+	case '=':
+		return TokenEqual, nil, nil
+	case '<':
+		if r.skipEqual() {
+			return TokenLessEqual, nil, nil
+		}
+		return TokenLess, nil, nil
+	case '>':
+		if r.skipEqual() {
+			return TokenGreaterEqual, nil, nil
+		}
+		return TokenGreater, nil, nil
+	case '!', '?':
+		if !r.skipEqual() {
+			break
+		} else if c == '!' {
+			return TokenNotEqual, nil, nil
+		} else {
+			return TokenExists, nil, nil
+		}
+	case '"':
+		b, err := r.readQuoted()
+		if err != nil {
+			return TokenInvalid, nil, err
+		}
+		return TokenQuoted, b, nil
+	}
+	b, err := r.readUnquoted(c)
+	if err != nil {
+		return TokenInvalid, nil, err
+	}
+	if id := tagID(b); id != TokenInvalid && r.peekNext('{') {
+		return id, nil, nil
+	}
+	return TokenUnquoted, b, nil
+}
+
+func tagID(b []byte) TokenID {
+	switch string(b) {
+	case "rgb":
+		return TokenRGB
+	case "hsv":
+		return TokenHSV
+	default:
+		return TokenInvalid
+	}
+}
+
+// peekNext reports whether the next non-space byte is c.
+func (r *TextReader) peekNext(c byte) bool {
+	for n := 1; ; n++ {
+		b, err := r.r.Peek(n)
+		if err != nil {
+			return false
+		} else if x := b[n-1]; !isSpace(x) {
+			return x == c
+		}
+	}
+}
+
+// skipEqual consumes the next byte if it is '=' and reports whether it did.
+func (r *TextReader) skipEqual() bool {
+	if b, err := r.r.Peek(1); err != nil || b[0] != '=' {
+		return false
+	}
+	_, _ = r.readByte()
+	return true
+}
+
+func (r *TextReader) skipSpace() (byte, error) {
+	for {
+		c, err := r.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case isSpace(c):
+		case c == '#':
+			if err := r.skipLine(); err != nil {
+				return 0, err
+			}
+		default:
+			return c, nil
+		}
+	}
+}
+
+func (r *TextReader) skipLine() error {
+	for {
+		c, err := r.readByte()
+		if err != nil {
+			return err
+		} else if c == '\n' {
+			return nil
+		}
+	}
+}
+
+func (r *TextReader) readWhitespace(first byte) ([]byte, error) {
+	r.buf = append(r.buf[:0], first)
+	for {
+		b, err := r.r.Peek(1)
+		if len(b) == 0 {
+			if err == io.EOF {
+				return r.buf, nil
+			}
+			return nil, err
+		} else if !isSpace(b[0]) {
+			return r.buf, nil
+		}
+		c, _ := r.readByte()
+		r.buf = append(r.buf, c)
+	}
+}
+
+// readComment reads the rest of the line after '#', leaving the line feed
+// to the next whitespace token.
+func (r *TextReader) readComment() ([]byte, error) {
+	r.buf = r.buf[:0]
+	for {
+		b, err := r.r.Peek(1)
+		if len(b) == 0 {
+			if err == io.EOF {
+				return r.buf, nil
+			}
+			return nil, err
+		} else if b[0] == '\n' {
+			return r.buf, nil
+		}
+		c, _ := r.readByte()
+		r.buf = append(r.buf, c)
+	}
+}
+
+func (r *TextReader) readQuoted() ([]byte, error) {
+	r.buf, r.raw = r.buf[:0], r.raw[:0]
+	for {
+		c, err := r.readByte()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+		switch c {
+		case '"':
+			return r.buf, nil
+		case '\\':
+			next, err := r.readByte()
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return nil, err
+			}
+			if r.opts.trivia {
+				r.raw = append(r.raw, c, next)
+			}
+			if next != '"' && next != '\\' {
+				r.buf = append(r.buf, c)
+			}
+			r.buf = append(r.buf, next)
+		default:
+			r.buf = append(r.buf, c)
+			if r.opts.trivia {
+				r.raw = append(r.raw, c)
+			}
+		}
+	}
+}
+
+func (r *TextReader) readUnquoted(first byte) ([]byte, error) {
+	r.buf = append(r.buf[:0], first)
+	if b, _ := r.r.Peek(1); first == '@' && len(b) == 1 && b[0] == '[' {
+		return r.readMath()
+	}
+	for {
+		b, err := r.r.Peek(2)
+		if len(b) == 0 {
+			if err == io.EOF {
+				return r.buf, nil
+			}
+			return nil, err
+		}
+		if isSpace(b[0]) || isDelimiter(b[0]) || isOperatorStart(b) {
+			return r.buf, nil
+		}
+		c, _ := r.readByte()
+		r.buf = append(r.buf, c)
+	}
+}
+
+// readMath reads an inline math expression such as @[a * 2] as one token.
+func (r *TextReader) readMath() ([]byte, error) {
+	for {
+		c, err := r.readByte()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+		r.buf = append(r.buf, c)
+		if c == ']' {
+			return r.buf, nil
+		}
+	}
+}
+
+func (r *TextReader) readByte() (byte, error) {
+	c, err := r.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	r.last = r.next
+	r.next.Offset++
+	if !r.last.IsValid() {
+		// Lines are unknown when reading started in the middle of the input.
+		return c, nil
+	} else if c == '\n' {
+		r.next.Line++
+		r.next.Column = 1
+	} else {
+		r.next.Column++
+	}
+	return c, nil
+}
+
+func isOperatorStart(b []byte) bool {
+	switch b[0] {
+	case '<', '>':
+		return true
+	case '!', '?':
+		return len(b) > 1 && b[1] == '='
+	default:
+		return false
+	}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '{', '}', '=', '"', '#':
+		return true
+	default:
+		return false
+	}
+}
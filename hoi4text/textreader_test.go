@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestVariables(t *testing.T) {
+	in := `@base = 5
+@double = @[base * 2]
+cost = @double
+bonus = @[ (base - 1) / 2 ]
+missing = @[base + unknown]
+`
+	r := hoi4text.NewScriptReader(strings.NewReader(in), hoi4text.WithVariables())
+	var got []string
+	for {
+		tok, err := r.ReadToken()
+		if target := (*hoi4text.UndefinedVariableError)(nil); errors.As(err, &target) {
+			want := hoi4text.Position{Offset: 94, Line: 5, Column: 20}
+			if target.Name != "unknown" || target.Position != want {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tok.String())
+	}
+	if want := "cost = 10 bonus = 2 missing ="; strings.Join(got, " ") != want {
+		t.Fatalf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestVariablesTrivia(t *testing.T) {
+	in := "@x = 5 # five\na = @x\n"
+	r := hoi4text.NewScriptReader(strings.NewReader(in), hoi4text.WithVariables(), hoi4text.WithTrivia())
+	var got []string
+	for {
+		tok, err := r.ReadToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tok.String())
+	}
+	if want := `" " "# five" "\n" "a" " " "=" " " "5" "\n"`; fmt.Sprintf("%q", got) != "["+want+"]" {
+		t.Fatalf("got %q, want [%s]", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TextWriter writes tokens in the text format. Feeding it every token read
+// by a [TextReader] created with [WithTrivia] reproduces the source, after
+// [TextWriter.WriteHeader] for a save. Without trivia, tokens are separated
+// only where required.
+type TextWriter struct {
+	w    io.Writer
+	buf  []byte
+	prev Token
+	opts options
+}
+
+func NewTextWriter(w io.Writer, opts ...Option) *TextWriter {
+	return &TextWriter{w: w, opts: newOptions(opts)}
+}
+
+// WriteHeader writes the header of a text save, [HeaderTxt]. It must be
+// called before any token.
+func (w *TextWriter) WriteHeader() error {
+	_, err := io.WriteString(w.w, HeaderTxt)
+	return err
+}
+
+func (w *TextWriter) WriteToken(t Token) error {
+	switch t.ID() {
+	case TokenQuoted:
+		s, err := encodeString(t.Quoted(), w.opts.encoding)
+		if err != nil {
+			return err
+		}
+		if raw, ok := t.raw(); ok {
+			if raw, err = encodeString(raw, w.opts.encoding); err != nil {
+				return err
+			}
+			t = quotedRaw(s, raw)
+		} else {
+			t = Quoted(s)
+		}
+	case TokenUnquoted:
+		s, err := encodeString(t.Unquoted(), w.opts.encoding)
+		if err != nil {
+			return err
+		}
+		t = Unquoted(s)
+	}
+	w.buf = w.buf[:0]
+	if w.needsSeparator(t) {
+		if w.prev.ID() == TokenComment {
+			w.buf = append(w.buf, '\n')
+		} else {
+			w.buf = append(w.buf, ' ')
+		}
+	}
+	var err error
+	w.buf, err = AppendText(w.buf, t)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	w.prev = t
+	return nil
+}
+
+func (w *TextWriter) needsSeparator(next Token) bool {
+	switch prev := w.prev.ID(); prev {
+	case TokenInvalid, TokenWhitespace, TokenOpen, TokenClose, TokenQuoted:
+		return false
+	case TokenComment:
+		return next.ID() != TokenWhitespace || !strings.HasPrefix(next.Whitespace(), "\n")
+	case TokenLess, TokenGreater:
+		return next.ID() == TokenEqual
+	case TokenUnquoted:
+		if next.ID() == TokenEqual {
+			s := w.prev.Unquoted()
+			return strings.HasSuffix(s, "!") || strings.HasSuffix(s, "?")
+		}
+		return isTextScalar(next.ID())
+	default:
+		return isTextScalar(prev) && isTextScalar(next.ID())
+	}
+}
+
+func isTextScalar(id TokenID) bool {
+	switch id {
+	case TokenU32, TokenU64, TokenI32, TokenI64, TokenF32, TokenF64,
+		TokenBool, TokenUnquoted:
+		return true
+	default:
+		return id.IsID()
+	}
+}
+
+// AppendText appends the text representation of t to dst.
+func AppendText(dst []byte, t Token) ([]byte, error) {
+	switch t.ID() {
+	case TokenInvalid:
+		return nil, ErrInvalidToken
+	case TokenU32:
+		return strconv.AppendUint(dst, uint64(t.getU32()), 10), nil
+	case TokenU64:
+		return strconv.AppendUint(dst, t.getU64(), 10), nil
+	case TokenI32:
+		return strconv.AppendInt(dst, int64(t.getI32()), 10), nil
+	case TokenI64:
+		return strconv.AppendInt(dst, t.getI64(), 10), nil
+	case TokenF32:
+		return strconv.AppendFloat(dst, float64(t.getF32()), 'f', -1, 32), nil
+	case TokenF64:
+		return strconv.AppendFloat(dst, t.getF64(), 'f', -1, 64), nil
+	case TokenBool:
+		if t.getBool() {
+			return append(dst, "yes"...), nil
+		}
+		return append(dst, "no"...), nil
+	case TokenQuoted:
+		if raw, ok := t.raw(); ok {
+			dst = append(dst, '"')
+			dst = append(dst, raw...)
+			return append(dst, '"'), nil
+		}
+		return appendQuoted(dst, t.getString()), nil
+	case TokenUnquoted, TokenWhitespace:
+		return append(dst, t.getString()...), nil
+	case TokenComment:
+		dst = append(dst, '#')
+		return append(dst, t.getString()...), nil
+	default:
+		if !t.ID().IsID() {
+			return append(dst, t.ID().String()...), nil
+		}
+		text := t.Resolve()
+		if text == "" {
+			return nil, ErrInvalidToken
+		}
+		return append(dst, text...), nil
+	}
+}
+
+// appendQuoted is the inverse of [TextReader.readQuoted].
+func appendQuoted(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			dst = append(dst, '\\', '"')
+		case '\\':
+			if i+1 == len(s) || s[i+1] == '"' || s[i+1] == '\\' {
+				dst = append(dst, '\\')
+			}
+			dst = append(dst, '\\')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, '"')
+}
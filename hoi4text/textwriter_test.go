@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestTextRoundTrip(t *testing.T) {
+	in := `# Germany
+GER_rhineland = {
+	cost = 10 # days
+	available={ has_war_support>=0.5 NOT = { tag = ITA } }
+	text = "quoted \"name\" C:\\"
+	path = "x\\y\z"
+}
+`
+	r := hoi4text.NewScriptReader(strings.NewReader(in), hoi4text.WithTrivia())
+	var out bytes.Buffer
+	w := hoi4text.NewTextWriter(&out)
+	s := hoi4text.NewScanner(r)
+	for s.Scan() {
+		tok := s.Token()
+		if tok.ID() == hoi4text.TokenQuoted && strings.HasPrefix(tok.Quoted(), "x") && tok.Quoted() != `x\y\z` {
+			t.Fatalf("got %q, want %q", tok.Quoted(), `x\y\z`)
+		}
+		if err := w.WriteToken(tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != in {
+		t.Fatalf("got %q, want %q", out.String(), in)
+	}
+}
+
+func TestTextRoundTripSave(t *testing.T) {
+	in := hoi4text.HeaderTxt + "\nplayer=\"FRA\" # player\n"
+	r, err := hoi4text.NewReader(strings.NewReader(in), hoi4text.WithTrivia())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	w := hoi4text.NewTextWriter(&out)
+	if err := w.WriteHeader(); err != nil {
+		t.Fatal(err)
+	}
+	s := hoi4text.NewScanner(r)
+	for s.Scan() {
+		if err := w.WriteToken(s.Token()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != in {
+		t.Fatalf("got %q, want %q", out.String(), in)
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	in := "player=\"Jos\xe9\"\nname=Fran\xe7ais\n"
+	r := hoi4text.NewScriptReader(strings.NewReader(in),
+		hoi4text.WithTrivia(), hoi4text.WithEncoding(hoi4text.EncodingAuto))
+	var out bytes.Buffer
+	w := hoi4text.NewTextWriter(&out, hoi4text.WithEncoding(hoi4text.EncodingWindows1252))
+	var strs []string
+	s := hoi4text.NewScanner(r)
+	for s.Scan() {
+		switch tok := s.Token(); tok.ID() {
+		case hoi4text.TokenQuoted:
+			strs = append(strs, tok.Quoted())
+		case hoi4text.TokenUnquoted:
+			strs = append(strs, tok.Unquoted())
+		}
+		if err := w.WriteToken(s.Token()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "player José name Français"; strings.Join(strs, " ") != want {
+		t.Fatalf("got %q, want %q", strings.Join(strs, " "), want)
+	}
+	if out.String() != in {
+		t.Fatalf("got %q, want %q", out.String(), in)
+	}
+}
@@ -37,7 +37,7 @@ func (t Token) Equal(other Token) bool {
 		return t.data == other.data
 	case TokenF64:
 		return t.getF64() == other.getF64()
-	case TokenQuoted, TokenUnquoted:
+	case TokenQuoted, TokenUnquoted, TokenComment, TokenWhitespace:
 		return t.getString() == other.getString()
 	default:
 		return true
@@ -63,7 +63,7 @@ func (t Token) Compare(other Token) int {
 		return cmp.Compare(t.getI64(), other.getI64())
 	case TokenF64:
 		return cmp.Compare(t.getF64(), other.getF64())
-	case TokenQuoted, TokenUnquoted:
+	case TokenQuoted, TokenUnquoted, TokenComment, TokenWhitespace:
 		return strings.Compare(t.getString(), other.getString())
 	default:
 		return 0
@@ -145,11 +145,56 @@ func (t Token) Unquoted() string {
 	return t.getString()
 }
 
+func (t Token) Comment() string {
+	if t.id != TokenComment {
+		panic("TokenID is not TokenComment")
+	}
+	return t.getString()
+}
+
+func (t Token) Whitespace() string {
+	if t.id != TokenWhitespace {
+		panic("TokenID is not TokenWhitespace")
+	}
+	return t.getString()
+}
+
 func (t Token) getString() string {
 	length := binary.NativeEndian.Uint64(t.data[:])
+	if length&rawQuoted != 0 {
+		length &= math.MaxUint32
+	}
 	return unsafe.String(t.ptr, length)
 }
 
+// rawQuoted marks the length of a [TokenQuoted] that also holds its source
+// text. The low 32 bits are the length of the string, the next 31 bits the
+// length of the source text stored after it.
+const rawQuoted = 1 << 63
+
+// quotedRaw returns a [TokenQuoted] for s that remembers raw, the text
+// between the quotes in the source.
+func quotedRaw(s, raw string) Token {
+	if len(s) > math.MaxUint32 || len(raw) > math.MaxInt32 {
+		return Quoted(s)
+	}
+	b := make([]byte, 0, len(s)+len(raw))
+	b = append(append(b, s...), raw...)
+	t := Token{id: TokenQuoted, ptr: unsafe.SliceData(b)}
+	binary.NativeEndian.PutUint64(t.data[:], uint64(len(s))|uint64(len(raw))<<32|rawQuoted)
+	return t
+}
+
+// raw returns the source text of a token created by [quotedRaw].
+func (t Token) raw() (string, bool) {
+	length := binary.NativeEndian.Uint64(t.data[:])
+	if t.id != TokenQuoted || length&rawQuoted == 0 {
+		return "", false
+	}
+	n := length & math.MaxUint32
+	return unsafe.String((*byte)(unsafe.Add(unsafe.Pointer(t.ptr), n)), length>>32&math.MaxInt32), true
+}
+
 func (t Token) F32() float32 {
 	if t.id != TokenF32 {
 		panic("TokenID is not TokenF32")
@@ -158,9 +203,25 @@ func (t Token) F32() float32 {
 }
 
 func (t Token) getF32() float32 {
+	if t.ptr == &fixedPoint {
+		return float32(t.getI32()) / 1000.0
+	}
 	return math.Float32frombits(binary.NativeEndian.Uint32(t.data[:]))
 }
 
+// RawF32 returns the fixed-point value of a [TokenF32], in thousandths.
+// exact reports whether the token was read from a binary source; otherwise
+// the value is rounded from the floating-point one.
+func (t Token) RawF32() (raw int32, exact bool) {
+	if t.id != TokenF32 {
+		panic("TokenID is not TokenF32")
+	}
+	if t.ptr == &fixedPoint {
+		return t.getI32(), true
+	}
+	return int32(math.Round(float64(t.getF32()) * 1000.0)), false
+}
+
 func (t Token) F64() float64 {
 	if t.id != TokenF64 {
 		panic("TokenID is not TokenF64")
@@ -169,9 +230,26 @@ func (t Token) F64() float64 {
 }
 
 func (t Token) getF64() float64 {
+	if t.ptr == &fixedPoint {
+		val := float64(t.getI64()) / 32768.0
+		return math.Floor(val*10_0000.0) / 10_0000.0
+	}
 	return math.Float64frombits(binary.NativeEndian.Uint64(t.data[:]))
 }
 
+// RawF64 returns the fixed-point value of a [TokenF64], in units of 1/32768.
+// exact reports whether the token was read from a binary source; otherwise
+// the value is rounded from the floating-point one.
+func (t Token) RawF64() (raw int64, exact bool) {
+	if t.id != TokenF64 {
+		panic("TokenID is not TokenF64")
+	}
+	if t.ptr == &fixedPoint {
+		return t.getI64(), true
+	}
+	return int64(math.Round(t.getF64() * 32768.0)), false
+}
+
 func (t Token) I64() int64 {
 	if t.id != TokenI64 {
 		panic("TokenID is not TokenI64")
@@ -183,6 +261,26 @@ func (t Token) getI64() int64 {
 	return int64(t.getU64()) //#nosec G115
 }
 
+// Resolve returns the text of an ID token using the [TokenResolver] of the
+// reader it was read from, or "" if the token is unknown.
+func (t Token) Resolve() string {
+	if !t.id.IsID() {
+		return ""
+	} else if t.ptr == nil {
+		return ResolveToken(t.id)
+	}
+	return (*resolverRef)(unsafe.Pointer(t.ptr)).r.ResolveToken(t.id)
+}
+
+// Name returns the name of the ID of t, as [TokenID.String] does, but
+// resolves ID tokens like [Token.Resolve].
+func (t Token) Name() string {
+	if t.id.IsID() {
+		return t.String()
+	}
+	return t.id.String()
+}
+
 // #endregion
 
 // #region Constructors
@@ -191,6 +289,12 @@ func ID(id TokenID) Token {
 	return Token{id: id}
 }
 
+// idToken returns an ID token that is resolved with ref, or with
+// [DefaultTokenResolver] if ref is nil.
+func idToken(id TokenID, ref *resolverRef) Token {
+	return Token{id: id, ptr: (*byte)(unsafe.Pointer(ref))}
+}
+
 func U32(i uint32) Token {
 	t := Token{id: TokenU32}
 	binary.NativeEndian.PutUint32(t.data[:], i)
@@ -229,6 +333,18 @@ func Unquoted(s string) Token {
 	return t
 }
 
+func Comment(s string) Token {
+	t := Token{id: TokenComment, ptr: unsafe.StringData(s)}
+	binary.NativeEndian.PutUint64(t.data[:], uint64(len(s)))
+	return t
+}
+
+func Whitespace(s string) Token {
+	t := Token{id: TokenWhitespace, ptr: unsafe.StringData(s)}
+	binary.NativeEndian.PutUint64(t.data[:], uint64(len(s)))
+	return t
+}
+
 func F32(f float32) Token {
 	t := Token{id: TokenF32}
 	binary.NativeEndian.PutUint32(t.data[:], math.Float32bits(f))
@@ -241,6 +357,24 @@ func F64(f float64) Token {
 	return t
 }
 
+// fixedPoint marks [TokenF32] and [TokenF64] tokens that hold the raw
+// fixed-point integer instead of a floating-point value.
+var fixedPoint byte
+
+// FixedF32 returns a [TokenF32] holding raw thousandths.
+func FixedF32(raw int32) Token {
+	t := Token{id: TokenF32, ptr: &fixedPoint}
+	binary.NativeEndian.PutUint32(t.data[:], uint32(raw)) //#nosec G115
+	return t
+}
+
+// FixedF64 returns a [TokenF64] holding raw units of 1/32768.
+func FixedF64(raw int64) Token {
+	t := Token{id: TokenF64, ptr: &fixedPoint}
+	binary.NativeEndian.PutUint64(t.data[:], uint64(raw)) //#nosec G115
+	return t
+}
+
 func I64(i int64) Token {
 	t := Token{id: TokenI64}
 	binary.NativeEndian.PutUint64(t.data[:], uint64(i)) //#nosec G115
@@ -266,8 +400,9 @@ func (t Token) String() string {
 		return "{"
 	case TokenClose:
 		return "}"
-	case TokenEqual:
-		return "="
+	case TokenEqual, TokenLess, TokenLessEqual, TokenGreater,
+		TokenGreaterEqual, TokenNotEqual, TokenExists:
+		return t.id.String()
 	case TokenU32:
 		return strconv.FormatUint(uint64(t.getU32()), 10)
 	case TokenU64:
@@ -280,14 +415,22 @@ func (t Token) String() string {
 		return strconv.Quote(t.getString())
 	case TokenUnquoted:
 		return t.getString()
+	case TokenComment:
+		return "#" + t.getString()
+	case TokenWhitespace:
+		return t.getString()
 	case TokenF32:
 		return strconv.FormatFloat(float64(t.getF32()), 'g', -1, 32)
 	case TokenF64:
 		return strconv.FormatFloat(t.getF64(), 'g', -1, 64)
 	case TokenI64:
 		return strconv.FormatInt(t.getI64(), 10)
+	case TokenRGB:
+		return "rgb"
+	case TokenHSV:
+		return "hsv"
 	default:
-		if text := ResolveToken(t.id); text != "" {
+		if text := t.Resolve(); text != "" {
 			return text
 		}
 		return "<unknown: " + strconv.FormatUint(uint64(t.id), 10) + ">"
@@ -24,6 +24,27 @@
 	TokenF32      TokenID = 0x000d
 	TokenF64      TokenID = 0x0167
 	TokenI64      TokenID = 0x0317
+	TokenRGB      TokenID = 0x0243
+	TokenHSV      TokenID = 0x0201
+)
+
+// Comparison operators. They only appear in text sources and use IDs that
+// the token table leaves unassigned. A [BinaryReader] rejects them.
+const (
+	TokenLess         TokenID = 0x0005
+	TokenLessEqual    TokenID = 0x0006
+	TokenGreater      TokenID = 0x0007
+	TokenGreaterEqual TokenID = 0x0008
+	TokenNotEqual     TokenID = 0x0009
+	TokenExists       TokenID = 0x000a
+)
+
+// Trivia tokens. They are only emitted by a [TextReader] created with
+// [WithTrivia] and are skipped by [BufferedReader] and [Decoder]. Their IDs
+// are unassigned in the token table, and a [BinaryReader] rejects them.
+const (
+	TokenComment    TokenID = 0x0010
+	TokenWhitespace TokenID = 0x0011
 )
 
 // Identifies if the given ID does not match of the predefined [TokenID]
@@ -32,13 +53,44 @@ func (id TokenID) IsID() bool {
 	switch id {
 	case TokenInvalid, TokenOpen, TokenClose, TokenEqual,
 		TokenU32, TokenU64, TokenI32, TokenBool, TokenQuoted,
-		TokenUnquoted, TokenF32, TokenF64, TokenI64:
+		TokenUnquoted, TokenF32, TokenF64, TokenI64, TokenRGB, TokenHSV,
+		TokenLess, TokenLessEqual, TokenGreater, TokenGreaterEqual,
+		TokenNotEqual, TokenExists, TokenComment, TokenWhitespace:
 		return false
 	default:
 		return true
 	}
 }
 
+// Identifies if the given ID is a key-value separator, that is [TokenEqual]
+// or one of the comparison operators.
+func (id TokenID) IsOperator() bool {
+	switch id {
+	case TokenEqual, TokenLess, TokenLessEqual, TokenGreater,
+		TokenGreaterEqual, TokenNotEqual, TokenExists:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTextOnly reports whether id is only produced by a [TextReader] and must
+// not occur in binary input.
+func (id TokenID) isTextOnly() bool {
+	return (id != TokenEqual && id.IsOperator()) || id.IsTrivia()
+}
+
+// Identifies if the given ID is [TokenComment] or [TokenWhitespace].
+func (id TokenID) IsTrivia() bool {
+	return id == TokenComment || id == TokenWhitespace
+}
+
+// Identifies if the given ID tags the container that follows it, as rgb and
+// hsv do in rgb { 12 34 56 }.
+func (id TokenID) IsTag() bool {
+	return id == TokenRGB || id == TokenHSV
+}
+
 func (id TokenID) Compare(other TokenID) int {
 	switch {
 	case id == other:
@@ -73,6 +125,22 @@ func (id TokenID) String() string {
 		return "}"
 	case TokenEqual:
 		return "="
+	case TokenLess:
+		return "<"
+	case TokenLessEqual:
+		return "<="
+	case TokenGreater:
+		return ">"
+	case TokenGreaterEqual:
+		return ">="
+	case TokenNotEqual:
+		return "!="
+	case TokenExists:
+		return "?="
+	case TokenComment:
+		return "comment"
+	case TokenWhitespace:
+		return "whitespace"
 	case TokenU32:
 		return "u32"
 	case TokenU64:
@@ -91,6 +159,10 @@ func (id TokenID) String() string {
 		return "f64"
 	case TokenI64:
 		return "i64"
+	case TokenRGB:
+		return "rgb"
+	case TokenHSV:
+		return "hsv"
 	default:
 		if text := ResolveToken(id); text != "" {
 			return text
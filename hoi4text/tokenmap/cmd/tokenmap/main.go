@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+// Command tokenmap merges token lists and writes the result as a token
+// list or as an encoded token map.
+//
+// Usage:
+//
+//	tokenmap [-override] -o output input...
+//
+// Each input may be a list of files separated like PATH.
+// The format of each file is chosen by its extension: .txt for text lists
+// ("0x1234 name"), .csv for CSV lists ("name;id") and anything else for
+// files in the [tokenmap] format. Entries that conflict with an earlier
+// input are reported and make the command fail without writing the output.
+// With -override, later inputs replace them instead.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text/tokenmap"
+)
+
+func main() {
+	output := flag.String("o", "", "output `file`")
+	override := flag.Bool("override", false, "let later inputs replace conflicting entries")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: tokenmap [-override] -o output input...") //nolint:errcheck
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *output == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*output, flag.Args(), *override); err != nil {
+		fmt.Fprintln(os.Stderr, "tokenmap:", err) //nolint:errcheck
+		os.Exit(1)
+	}
+}
+
+func run(output string, inputs []string, override bool) error {
+	m := make(map[uint16]string)
+	var conflicts int
+	for _, arg := range inputs {
+		// go generate passes each variable as one argument, so arguments
+		// are lists of files like PATH.
+		for _, name := range filepath.SplitList(arg) {
+			src, err := readFile(name)
+			if err != nil {
+				return err
+			}
+			for _, c := range tokenmap.Merge(m, src, override) {
+				fmt.Fprintf(os.Stderr, "%s: 0x%04x is %q, was %q\n", name, c.Key, c.New, c.Old) //nolint:errcheck
+				conflicts++
+			}
+		}
+	}
+	if conflicts > 0 && !override {
+		return fmt.Errorf("%d conflicts", conflicts)
+	}
+	// Keep an equal output as is, since encoders may differ in the bytes they
+	// produce.
+	if old, err := readFile(output); err == nil && maps.Equal(old, m) {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, output, m); err != nil {
+		return err
+	}
+	return os.WriteFile(output, buf.Bytes(), 0o644) //#nosec G306
+}
+
+func readFile(name string) (map[uint16]string, error) {
+	f, err := os.Open(name) //#nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	var m map[uint16]string
+	switch filepath.Ext(name) {
+	case ".txt":
+		m, err = tokenmap.ReadText(f)
+	case ".csv":
+		m, err = tokenmap.ReadCSV(f)
+	default:
+		m, err = tokenmap.Decode(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+func write(w io.Writer, name string, m map[uint16]string) error {
+	switch filepath.Ext(name) {
+	case ".txt":
+		return tokenmap.WriteText(w, m)
+	case ".csv":
+		return tokenmap.WriteCSV(w, m)
+	default:
+		_, err := tokenmap.Encode(w, m)
+		return err
+	}
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package tokenmap
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports an invalid line of a text or CSV token list.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+// ReadText reads a token list with one "0x1234 name" entry per line. Keys
+// may also be decimal. Blank lines and lines starting with # are ignored.
+func ReadText(r io.Reader) (map[uint16]string, error) {
+	m := make(map[uint16]string)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) != 2 {
+			return nil, &SyntaxError{line, "want a key and a name"}
+		}
+		if err := addEntry(m, fields[0], fields[1], line); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteText writes m in the format read by [ReadText], in ascending key
+// order.
+func WriteText(w io.Writer, m map[uint16]string) error {
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		buf = append(buf[:0], "0x"...)
+		buf = appendHex16(buf, key)
+		buf = append(buf, ' ')
+		buf = append(buf, m[key]...)
+		buf = append(buf, '\n')
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func appendHex16(dst []byte, x uint16) []byte {
+	const digits = "0123456789abcdef"
+	return append(dst, digits[x>>12], digits[x>>8&0xf], digits[x>>4&0xf], digits[x&0xf])
+}
+
+// ReadCSV reads a token list with one "name;id" record per line. IDs may be
+// decimal or hexadecimal with a 0x prefix. Lines starting with # are
+// ignored.
+func ReadCSV(r io.Reader) (map[uint16]string, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = ';'
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	m := make(map[uint16]string)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return m, nil
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, &SyntaxError{pe.Line, pe.Err.Error()}
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if err = addEntry(m, strings.TrimSpace(record[1]), strings.TrimSpace(record[0]), line); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WriteCSV writes m in the format read by [ReadCSV], in ascending key order.
+func WriteCSV(w io.Writer, m map[uint16]string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	record := make([]string, 2)
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		record[0], record[1] = m[key], strconv.FormatUint(uint64(key), 10)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func addEntry(m map[uint16]string, key, value string, line int) error {
+	k, err := strconv.ParseUint(key, 0, 16)
+	if err != nil {
+		return &SyntaxError{line, "invalid key " + strconv.Quote(key)}
+	} else if value == "" {
+		return &SyntaxError{line, "token value cannot be empty"}
+	}
+	if old, ok := m[uint16(k)]; ok && old != value {
+		return &SyntaxError{line, "key " + key + " is already " + strconv.Quote(old)}
+	}
+	m[uint16(k)] = value
+	return nil
+}
+
+// Conflict is a key that two tables map to different values.
+type Conflict struct {
+	Key      uint16
+	Old, New string
+}
+
+// Merge copies the entries of src into dst and returns the keys that are in
+// both with different values, in ascending key order. Conflicting entries
+// keep their value in dst unless override is true.
+func Merge(dst, src map[uint16]string, override bool) []Conflict {
+	var conflicts []Conflict
+	for _, key := range slices.Sorted(maps.Keys(src)) {
+		value := src[key]
+		if old, ok := dst[key]; ok && old != value {
+			conflicts = append(conflicts, Conflict{key, old, value})
+			if !override {
+				continue
+			}
+		}
+		dst[key] = value
+	}
+	return conflicts
+}
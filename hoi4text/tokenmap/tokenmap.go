@@ -5,11 +5,14 @@
 
 import (
 	"bufio"
+	"cmp"
 	"compress/flate"
 	"encoding/binary"
 	"errors"
 	"io"
+	"iter"
 	"maps"
+	"math"
 	"slices"
 	"unsafe"
 )
@@ -66,19 +69,73 @@ func Encode(w io.Writer, m map[uint16]string) (n int, err error) {
 }
 
 func Decode(r io.Reader) (map[uint16]string, error) {
+	t, err := DecodeTable(r)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint16]string, t.Len())
+	for key, value := range t.All() {
+		m[key] = value
+	}
+	return m, nil
+}
+
+// Table is a read-only token table. It stores all values in one string and
+// finds them through a slice of offsets indexed by key, so lookups do not
+// allocate.
+type Table struct {
+	// The value of key k is data[starts[k]:starts[k+1]] if bit k of present
+	// is set.
+	starts  []uint32
+	present []uint64
+	data    string
+	len     int
+}
+
+func (t *Table) Lookup(key uint16) (string, bool) {
+	if int(key)+1 >= len(t.starts) || t.present[key/64]&(1<<(key%64)) == 0 {
+		return "", false
+	}
+	return t.data[t.starts[key]:t.starts[key+1]], true
+}
+
+func (t *Table) Len() int {
+	return t.len
+}
+
+// All returns the entries of t in ascending key order.
+func (t *Table) All() iter.Seq2[uint16, string] {
+	return func(yield func(uint16, string) bool) {
+		for key := range len(t.starts) - 1 {
+			if value, ok := t.Lookup(uint16(key)); ok && !yield(uint16(key), value) { //#nosec G115
+				return
+			}
+		}
+	}
+}
+
+type tableEntry struct {
+	key        uint16
+	start, end uint32
+}
+
+func DecodeTable(r io.Reader) (*Table, error) {
 	fr := flate.NewReader(r)
 	br := bufio.NewReader(fr)
 	allValuesLen, err := binary.ReadUvarint(br)
 	if err != nil {
 		return nil, err
+	} else if allValuesLen > math.MaxUint32 {
+		return nil, errors.New("values are too long")
 	}
 	mapLen, err := binary.ReadUvarint(br)
 	if err != nil {
 		return nil, err
 	}
 	allValues := make([]byte, allValuesLen)
-	m := make(map[uint16]string, mapLen)
+	entries := make([]tableEntry, 0, min(mapLen, math.MaxUint16+1))
 	keyBuf := make([]byte, 2)
+	var offset uint32
 	for range mapLen {
 		if _, err := io.ReadFull(br, keyBuf); err != nil {
 			return nil, err
@@ -87,19 +144,80 @@ func Decode(r io.Reader) (map[uint16]string, error) {
 		valueLen, err := binary.ReadUvarint(br)
 		if err != nil {
 			return nil, err
-		} else if uint64(len(allValues)) < valueLen {
+		} else if uint64(len(allValues))-uint64(offset) < valueLen {
 			return nil, errors.New("value length exceeds remaining buffer capacity")
 		}
-		valueBuf := allValues[:valueLen]
-		allValues = allValues[valueLen:]
-		if _, err := io.ReadFull(br, valueBuf); err != nil {
+		end := offset + uint32(valueLen) //#nosec G115
+		if _, err := io.ReadFull(br, allValues[offset:end]); err != nil {
 			return nil, err
 		}
-		value := unsafe.String(unsafe.SliceData(valueBuf), len(valueBuf))
-		m[key] = value
+		entries = append(entries, tableEntry{key, offset, end})
+		offset = end
 	}
 	if err := fr.Close(); err != nil {
 		return nil, err
 	}
-	return m, nil
+	return newTable(entries, allValues), nil
+}
+
+// newTable builds a [Table] from entries whose values are stored in data.
+// Like a map, later entries replace earlier ones with the same key.
+func newTable(entries []tableEntry, data []byte) *Table {
+	if !slices.IsSortedFunc(entries, compareEntries) {
+		slices.SortStableFunc(entries, compareEntries)
+	}
+	n := 0
+	for i, e := range entries {
+		if i+1 < len(entries) && entries[i+1].key == e.key {
+			continue
+		}
+		entries[n] = e
+		n++
+	}
+	entries = entries[:n]
+	// The values must be stored in key order. This is already the case for
+	// tables written by Encode.
+	var offset uint32
+	for _, e := range entries {
+		if e.start != offset {
+			data = reorderValues(entries, data)
+			break
+		}
+		offset = e.end
+	}
+	t := &Table{data: unsafe.String(unsafe.SliceData(data), len(data)), len: len(entries)}
+	if len(entries) == 0 {
+		return t
+	}
+	t.starts = make([]uint32, int(entries[len(entries)-1].key)+2)
+	t.present = make([]uint64, (len(t.starts)+62)/64)
+	for _, e := range entries {
+		t.present[e.key/64] |= 1 << (e.key % 64)
+	}
+	i := 0
+	for key := range t.starts {
+		if i == len(entries) {
+			t.starts[key] = entries[i-1].end
+			continue
+		}
+		t.starts[key] = entries[i].start
+		if int(entries[i].key) == key {
+			i++
+		}
+	}
+	return t
+}
+
+func compareEntries(x, y tableEntry) int {
+	return cmp.Compare(x.key, y.key)
+}
+
+func reorderValues(entries []tableEntry, data []byte) []byte {
+	dst := make([]byte, 0, len(data))
+	for i, e := range entries {
+		start := len(dst)
+		dst = append(dst, data[e.start:e.end]...)
+		entries[i].start, entries[i].end = uint32(start), uint32(len(dst)) //#nosec G115
+	}
+	return dst
 }
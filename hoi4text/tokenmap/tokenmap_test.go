@@ -5,8 +5,12 @@
 
 import (
 	"bytes"
+	"compress/flate"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -28,6 +32,121 @@ func BenchmarkDecode(b *testing.B) {
 	}
 }
 
+func BenchmarkDecodeTable(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := DecodeTable(bytes.NewReader(encoded)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLookupMap(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = decoded[377]
+	}
+}
+
+func BenchmarkLookupTable(b *testing.B) {
+	t, err := DecodeTable(bytes.NewReader(encoded))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = t.Lookup(377)
+	}
+}
+
+func TestDecodeTable(t *testing.T) {
+	table, err := DecodeTable(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Len() != len(decoded) {
+		t.Fatalf("got %d entries, want %d", table.Len(), len(decoded))
+	}
+	for key, value := range table.All() {
+		if decoded[key] != value {
+			t.Fatalf("got %q for %d, want %q", value, key, decoded[key])
+		}
+	}
+	if _, ok := table.Lookup(0xffff); ok {
+		t.Fatal("found a missing key")
+	}
+}
+
+func TestDecodeEmptyValue(t *testing.T) {
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	// 1 byte of values in 2 entries: 0x0001 "" and 0x0002 "a".
+	if _, err := fw.Write([]byte{1, 2, 1, 0, 0, 2, 0, 1, 'a'}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	} else if !maps.Equal(m, map[uint16]string{1: "", 2: "a"}) {
+		t.Fatal(m)
+	}
+	table, err := DecodeTable(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := table.Lookup(1); !ok || value != "" {
+		t.Fatalf("got %q, %v for 1", value, ok)
+	} else if _, ok := table.Lookup(0); ok {
+		t.Fatal("found a missing key")
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, f := range []struct {
+		name  string
+		write func(io.Writer, map[uint16]string) error
+		read  func(io.Reader) (map[uint16]string, error)
+	}{
+		{"text", WriteText, ReadText},
+		{"csv", WriteCSV, ReadCSV},
+	} {
+		var buf bytes.Buffer
+		if err := f.write(&buf, decoded); err != nil {
+			t.Fatal(f.name, err)
+		}
+		m, err := f.read(&buf)
+		if err != nil {
+			t.Fatal(f.name, err)
+		} else if !maps.Equal(m, decoded) {
+			t.Fatal(f.name, "tokens differ after a round trip")
+		}
+	}
+	m, err := ReadText(strings.NewReader("# comment\n\n0x0010 foo\n17 bar\n"))
+	if err != nil || !maps.Equal(m, map[uint16]string{16: "foo", 17: "bar"}) {
+		t.Fatal(m, err)
+	}
+	if _, err = ReadCSV(strings.NewReader("foo;1\nbar;1\n")); err == nil || err.Error() != `line 2: key 1 is already "foo"` {
+		t.Fatal(err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := map[uint16]string{1: "a", 2: "b"}
+	conflicts := Merge(dst, map[uint16]string{2: "c", 3: "d"}, false)
+	if !slices.Equal(conflicts, []Conflict{{2, "b", "c"}}) {
+		t.Fatal(conflicts)
+	} else if !maps.Equal(dst, map[uint16]string{1: "a", 2: "b", 3: "d"}) {
+		t.Fatal(dst)
+	}
+	Merge(dst, map[uint16]string{2: "c"}, true)
+	if dst[2] != "c" {
+		t.Fatal(dst)
+	}
+}
+
 func init() {
 	var err error
 	encoded, err = os.ReadFile("../tokens")
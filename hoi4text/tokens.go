@@ -6,23 +6,94 @@
 import (
 	_ "embed"
 	"strings"
+	"sync"
 
 	"github.com/antoniszymanski/hoi4-go/hoi4text/tokenmap"
 )
 
+// TokenResolver maps the IDs of binary tokens to their text.
+type TokenResolver interface {
+	// ResolveToken returns the text of id, or "" if id is unknown.
+	ResolveToken(id TokenID) string
+}
+
+// TokenMap is a [TokenResolver] backed by a map, such as one returned by
+// [tokenmap.Decode].
+type TokenMap map[uint16]string
+
+func (m TokenMap) ResolveToken(id TokenID) string {
+	return m[uint16(id)]
+}
+
+// tableResolver is a [TokenResolver] backed by a [tokenmap.Table].
+type tableResolver struct {
+	t *tokenmap.Table
+}
+
+func (r tableResolver) ResolveToken(id TokenID) string {
+	text, _ := r.t.Lookup(uint16(id))
+	return text
+}
+
+// DefaultTokenResolver resolves tokens with the embedded token table.
+var DefaultTokenResolver TokenResolver = embeddedTokens{}
+
+type embeddedTokens struct{}
+
+func (embeddedTokens) ResolveToken(id TokenID) string {
+	return ResolveToken(id)
+}
+
+// ResolveToken resolves id with the embedded token table, which is decoded
+// on first use.
 func ResolveToken(id TokenID) string {
-	return tokens[uint16(id)]
+	text, _ := tokens().Lookup(uint16(id))
+	return text
 }
 
-func init() {
-	var err error
-	tokens, err = tokenmap.Decode(strings.NewReader(tokensData))
-	if err != nil {
-		panic(err)
+// LookupToken returns the ID of the token with the given text in the
+// embedded token table. If several IDs share the text, the lowest one is
+// returned.
+func LookupToken(name string) (TokenID, bool) {
+	id, ok := tokenIDs()[name]
+	return TokenID(id), ok
+}
+
+var tokenIDs = sync.OnceValue(func() map[string]uint16 {
+	m := make(map[string]uint16, tokens().Len())
+	for id, name := range tokens().All() {
+		if _, ok := m[name]; !ok {
+			m[name] = id
+		}
+	}
+	return m
+})
+
+// WithTokenResolver sets the [TokenResolver] used for the ID tokens read
+// by a reader or decoder, instead of [DefaultTokenResolver].
+func WithTokenResolver(r TokenResolver) Option {
+	return func(o *options) {
+		o.resolver = &resolverRef{r}
 	}
 }
 
-var tokens map[uint16]string
+// resolverRef is referenced by the ptr field of ID tokens read with a
+// custom [TokenResolver].
+type resolverRef struct {
+	r TokenResolver
+}
+
+var tokens = sync.OnceValue(func() *tokenmap.Table {
+	t, err := tokenmap.DecodeTable(strings.NewReader(tokensData))
+	if err != nil {
+		panic(err)
+	}
+	return t
+})
 
+// Set HOI4_TOKENS to a PATH-style list of token lists to merge into tokens,
+// see tokenmap/cmd/tokenmap.
+//
+//go:generate go run ./tokenmap/cmd/tokenmap -o tokens tokens $HOI4_TOKENS
 //go:embed tokens
 var tokensData string
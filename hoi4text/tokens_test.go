@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+)
+
+func TestLookupToken(t *testing.T) {
+	id, ok := hoi4text.LookupToken("checksum")
+	if !ok || hoi4text.ResolveToken(id) != "checksum" {
+		t.Fatalf("got %v, %v", id, ok)
+	}
+	if _, ok := hoi4text.LookupToken("not a token"); ok {
+		t.Fatal("found a token that does not exist")
+	}
+}
+
+func BenchmarkLookupToken(b *testing.B) {
+	for b.Loop() {
+		_, _ = hoi4text.LookupToken("checksum")
+	}
+}
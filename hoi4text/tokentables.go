@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text/tokenmap"
+)
+
+// GameVersion is a game version such as 1.14.8.0.
+type GameVersion [4]uint16
+
+// ParseGameVersion parses the first dotted version number in s, so that
+// the value of a save's version entry, such as "Trenches v1.14.8.0 (39a3)",
+// can be parsed directly. Missing components are zero.
+func ParseGameVersion(s string) (GameVersion, bool) {
+	start := strings.IndexAny(s, "0123456789")
+	if start < 0 {
+		return GameVersion{}, false
+	}
+	s = s[start:]
+	if end := strings.IndexFunc(s, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	}); end >= 0 {
+		s = s[:end]
+	}
+	var v GameVersion
+	for i, part := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if i == len(v) {
+			break
+		}
+		x, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return GameVersion{}, false
+		}
+		v[i] = uint16(x)
+	}
+	return v, true
+}
+
+func (v GameVersion) Compare(w GameVersion) int {
+	return slices.Compare(v[:], w[:])
+}
+
+func (v GameVersion) String() string {
+	var dst []byte
+	for i, x := range v {
+		if i > 0 {
+			dst = append(dst, '.')
+		}
+		dst = strconv.AppendUint(dst, uint64(x), 10)
+	}
+	return string(dst)
+}
+
+// TokenTables is a registry of token tables for game versions.
+type TokenTables struct {
+	tables []versionedTable // sorted by version
+}
+
+type versionedTable struct {
+	version GameVersion
+	r       TokenResolver
+}
+
+// Register adds the table for version, replacing any previous one.
+func (t *TokenTables) Register(version GameVersion, r TokenResolver) {
+	i, found := slices.BinarySearchFunc(t.tables, version, func(e versionedTable, v GameVersion) int {
+		return e.version.Compare(v)
+	})
+	if found {
+		t.tables[i].r = r
+	} else {
+		t.tables = slices.Insert(t.tables, i, versionedTable{version, r})
+	}
+}
+
+// Load registers the table for version read from r in the [tokenmap]
+// format.
+func (t *TokenTables) Load(version GameVersion, r io.Reader) error {
+	table, err := tokenmap.DecodeTable(r)
+	if err != nil {
+		return err
+	}
+	t.Register(version, tableResolver{table})
+	return nil
+}
+
+// LoadDir registers every table in dir. Each file must be named after its
+// game version, optionally with an extension, for example 1.14.8.tokens.
+func (t *TokenTables) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		name := e.Name()
+		if name[0] < '0' || name[0] > '9' {
+			continue
+		}
+		version, ok := ParseGameVersion(name)
+		if !ok {
+			continue
+		}
+		if err = t.loadFile(version, filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TokenTables) loadFile(version GameVersion, name string) error {
+	f, err := os.Open(name) //#nosec G304
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	return t.Load(version, f)
+}
+
+// Select returns the table for version, or the table of the closest older
+// version if there is none.
+func (t *TokenTables) Select(version GameVersion) (TokenResolver, bool) {
+	i, found := slices.BinarySearchFunc(t.tables, version, func(e versionedTable, v GameVersion) int {
+		return e.version.Compare(v)
+	})
+	if found {
+		return t.tables[i].r, true
+	} else if i > 0 {
+		return t.tables[i-1].r, true
+	}
+	return nil, false
+}
+
+// WithTokenTables makes binary readers select the token table for the game
+// version of the save from t. The version is read from the top-level
+// version entry, which must be among the first 1024 tokens of the save, even
+// for decoders created at an offset by [NewDecoderAt] or
+// [IndexEntry.NewDecoder].
+// If no table matches, the resolver set by [WithTokenResolver] or
+// [DefaultTokenResolver] is used.
+func WithTokenTables(t *TokenTables) Option {
+	return func(o *options) {
+		o.tables = t
+	}
+}
+
+// versionSearchTokens is the number of tokens searched for the version
+// entry.
+const versionSearchTokens = 1024
+
+func withTokenTables(r *BinaryReader) Reader {
+	if r.opts.tables == nil {
+		return r
+	}
+	return &tableReader{r: r, tables: r.opts.tables}
+}
+
+// selectTableAt returns the resolver for the version entry at the start of
+// the binary save read through ra, as the section of a reader created at an
+// offset does not contain it.
+func selectTableAt(ra io.ReaderAt, opts options) *resolverRef {
+	sr := io.NewSectionReader(ra, int64(HeaderLen), math.MaxInt64-int64(HeaderLen))
+	r := &tableReader{r: &BinaryReader{r: bufio.NewReader(sr), opts: opts}, tables: opts.tables}
+	r.selectTable()
+	return r.r.opts.resolver
+}
+
+// tableReader reads ahead of a [BinaryReader] to find the version of the
+// save and select its token table before returning any tokens.
+type tableReader struct {
+	r        *BinaryReader
+	tables   *TokenTables
+	buf      []bufferedToken
+	selected bool
+	// buffered reports whether the last token was returned from buf.
+	buffered bool
+	offset   uint64
+	pos      Position
+}
+
+func (r *tableReader) Offset() uint64 {
+	if !r.selected || r.buffered {
+		return r.offset
+	}
+	return r.r.Offset()
+}
+
+func (r *tableReader) Position() Position {
+	if !r.selected || r.buffered {
+		return r.pos
+	}
+	return r.r.Position()
+}
+
+func (r *tableReader) ReadToken() (Token, error) {
+	if !r.selected {
+		r.selectTable()
+	}
+	if r.buffered = len(r.buf) > 0; !r.buffered {
+		return r.r.ReadToken()
+	}
+	t := r.buf[0]
+	r.buf = r.buf[1:]
+	r.offset, r.pos = t.offset, t.pos
+	return t.token, t.err
+}
+
+func (r *tableReader) SkipToken() (TokenID, error) {
+	if !r.selected || len(r.buf) > 0 {
+		t, err := r.ReadToken()
+		return t.ID(), err
+	}
+	r.buffered = false
+	return r.r.SkipToken()
+}
+
+func (r *tableReader) selectTable() {
+	r.selected = true
+	var depth int
+	var version string
+	for len(r.buf) < versionSearchTokens && version == "" {
+		t, err := r.r.ReadToken()
+		r.buf = append(r.buf, bufferedToken{t, err, r.r.Offset(), r.r.Position()})
+		if err != nil {
+			break
+		}
+		switch t.ID() {
+		case TokenOpen:
+			depth++
+		case TokenClose:
+			depth--
+		case TokenQuoted:
+			if n := len(r.buf); depth == 0 && n >= 3 &&
+				r.buf[n-2].token.ID() == TokenEqual &&
+				r.buf[n-3].token.Resolve() == "version" {
+				version = strings.Clone(t.Quoted())
+			}
+		}
+	}
+	v, ok := ParseGameVersion(version)
+	if !ok {
+		return
+	}
+	table, ok := r.tables.Select(v)
+	if !ok {
+		return
+	}
+	ref := &resolverRef{table}
+	r.r.opts.resolver = ref
+	for i, t := range r.buf {
+		if t.token.ID().IsID() {
+			r.buf[i].token = idToken(t.token.ID(), ref)
+		}
+	}
+}
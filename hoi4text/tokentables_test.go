@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antoniszymanski/hoi4-go/hoi4text"
+	"github.com/antoniszymanski/hoi4-go/hoi4text/tokenmap"
+)
+
+func TestTokenTables(t *testing.T) {
+	dir := t.TempDir()
+	for name, m := range map[string]map[uint16]string{
+		"1.12.tokens": {0xfff0: "old"},
+		"1.14":        {0xfff0: "new"},
+	} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tokenmap.Encode(f, m); err != nil {
+			t.Fatal(err)
+		}
+		if err = f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var tables hoi4text.TokenTables
+	if err := tables.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	versionID, _ := hoi4text.LookupToken("version")
+
+	for _, tt := range []struct {
+		version string
+		want    string
+	}{
+		{"Trenches v1.14.8.0 (39a3)", "new"},
+		{"v1.13.2", "old"},
+		{"v1.11", "<unknown: 65520>"},
+		{"", "<unknown: 65520>"},
+	} {
+		in := binary.LittleEndian.AppendUint16([]byte(hoi4text.HeaderBin), uint16(versionID))
+		in = binary.LittleEndian.AppendUint16(in, uint16(hoi4text.TokenEqual))
+		in = appendString(in, hoi4text.TokenQuoted, tt.version)
+		in = binary.LittleEndian.AppendUint16(in, 0xfff0)
+		r, err := hoi4text.NewReaderBytes(in, hoi4text.WithTokenTables(&tables))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var last hoi4text.Token
+		for range 4 {
+			if last, err = r.ReadToken(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := last.String(); got != tt.want {
+			t.Errorf("version %q: got %q, want %q", tt.version, got, tt.want)
+		}
+		if want := uint64(len(in) - hoi4text.HeaderLen); r.Offset() != want {
+			t.Errorf("version %q: got offset %d, want %d", tt.version, r.Offset(), want)
+		}
+
+		// A decoder created at an offset reads the version from the start.
+		dec, err := hoi4text.NewDecoderAt(bytes.NewReader(in), uint64(len(in)-hoi4text.HeaderLen-2), 0, hoi4text.WithTokenTables(&tables))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last, err = dec.ReadToken(); err != nil {
+			t.Fatal(err)
+		} else if got := last.String(); got != tt.want {
+			t.Errorf("version %q at an offset: got %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
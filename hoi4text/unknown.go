@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+// UnknownToken describes an ID token that the [TokenResolver] could not
+// resolve.
+type UnknownToken struct {
+	ID    TokenID
+	Count uint64
+	// Position of the first occurrence.
+	Position Position
+	// Keys leading to the first occurrence.
+	Path []string
+}
+
+// WithUnknownTokens makes a [Decoder] collect the ID tokens that its
+// [TokenResolver] cannot resolve, including skipped ones, see
+// [Decoder.UnknownTokens]. Unmarshaling with this option skips entries with
+// unknown keys and leaves strings with unknown values unchanged instead of
+// failing.
+func WithUnknownTokens() Option {
+	return func(o *options) {
+		o.unknown = true
+	}
+}
+
+// unknownReader records the unknown tokens read through it.
+type unknownReader struct {
+	r       Reader
+	path    keyPath
+	unknown map[TokenID]UnknownToken
+}
+
+func (r *unknownReader) Offset() uint64 {
+	return r.r.Offset()
+}
+
+func (r *unknownReader) Position() Position {
+	return position(r.r)
+}
+
+func (r *unknownReader) ReadToken() (Token, error) {
+	t, err := r.r.ReadToken()
+	if err != nil {
+		return t, err
+	}
+	r.path.update(t)
+	if t.id.IsID() && t.Resolve() == "" {
+		u, ok := r.unknown[t.id]
+		if !ok {
+			u = UnknownToken{ID: t.id, Position: r.Position(), Path: r.path.keys()}
+		}
+		u.Count++
+		r.unknown[t.id] = u
+	}
+	return t, nil
+}
+
+// SkipToken reads the token, so that a skipped unknown token is recorded.
+func (r *unknownReader) SkipToken() (TokenID, error) {
+	t, err := r.ReadToken()
+	return t.ID(), err
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2026 Antoni Szymański
+// SPDX-License-Identifier: MPL-2.0
+
+package hoi4text
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+func isVariable(id TokenID, b []byte) bool {
+	return id == TokenUnquoted && len(b) > 1 && b[0] == '@'
+}
+
+// variable handles a token starting with '@'. It returns false if the token
+// began a definition, which is consumed without producing a token.
+func (r *TextReader) variable(b []byte) (Token, bool, error) {
+	start := r.start
+	name := string(b)
+	if expr, ok := strings.CutPrefix(name, "@["); ok {
+		expr = strings.TrimSuffix(expr, "]")
+		x, err := evaluate(expr, r.vars, start.advance("@["))
+		if err != nil {
+			return Token{}, false, err
+		}
+		return numberToken(x), true, nil
+	}
+	name = name[1:]
+	if !r.peekNext('=') {
+		t, ok := r.vars[name]
+		if !ok {
+			return Token{}, false, &UndefinedVariableError{name, start}
+		}
+		return t, true, nil
+	}
+	if _, _, err := r.scanSignificant(); err != nil {
+		return Token{}, false, err
+	}
+	// Trivia inside a definition is dropped along with it.
+	t, err := r.ReadToken()
+	for err == nil && t.ID().IsTrivia() {
+		t, err = r.ReadToken()
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+	switch t.ID() {
+	case TokenUnquoted:
+		if x, err := strconv.ParseFloat(t.Unquoted(), 64); err == nil {
+			t = numberToken(x)
+		}
+	case TokenOpen, TokenClose:
+		return Token{}, false, &UnexpectedTokenError{t, BeginningOfValue, r.start}
+	}
+	if r.vars == nil {
+		r.vars = make(map[string]Token)
+	}
+	r.vars[name] = t
+	return Token{}, false, nil
+}
+
+func (r *TextReader) scanSignificant() (TokenID, []byte, error) {
+	for {
+		id, b, err := r.scan()
+		if err != nil || !id.IsTrivia() {
+			return id, b, err
+		}
+	}
+}
+
+func numberToken(x float64) Token {
+	switch {
+	case x != math.Trunc(x):
+		return F64(x)
+	case x >= math.MinInt32 && x <= math.MaxInt32:
+		return I32(int32(x))
+	case x >= math.MinInt64 && x < math.MaxInt64:
+		return I64(int64(x))
+	default:
+		return F64(x)
+	}
+}
+
+func tokenNumber(t Token) (float64, bool) {
+	switch t.ID() {
+	case TokenU32:
+		return float64(t.U32()), true
+	case TokenU64:
+		return float64(t.U64()), true
+	case TokenI32:
+		return float64(t.I32()), true
+	case TokenI64:
+		return float64(t.I64()), true
+	case TokenF32:
+		return float64(t.F32()), true
+	case TokenF64:
+		return t.F64(), true
+	default:
+		return 0, false
+	}
+}
+
+// evaluate computes an inline math expression. pos is the position of expr
+// in the source and is used for errors.
+func evaluate(expr string, vars map[string]Token, pos Position) (float64, error) {
+	p := exprParser{s: expr, vars: vars, pos: pos}
+	x, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.i != len(p.s) {
+		return 0, p.invalid()
+	}
+	return x, nil
+}
+
+type exprParser struct {
+	s    string
+	i    int
+	vars map[string]Token
+	pos  Position
+}
+
+func (p *exprParser) sum() (float64, error) {
+	x, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.i == len(p.s) || (p.s[p.i] != '+' && p.s[p.i] != '-') {
+			return x, nil
+		}
+		op := p.s[p.i]
+		p.i++
+		y, err := p.product()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			x += y
+		} else {
+			x -= y
+		}
+	}
+}
+
+func (p *exprParser) product() (float64, error) {
+	x, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.i == len(p.s) || (p.s[p.i] != '*' && p.s[p.i] != '/') {
+			return x, nil
+		}
+		op := p.s[p.i]
+		p.i++
+		y, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			x *= y
+		} else if y == 0 {
+			return 0, p.invalid()
+		} else {
+			x /= y
+		}
+	}
+}
+
+func (p *exprParser) unary() (float64, error) {
+	p.skipSpace()
+	if p.i < len(p.s) && p.s[p.i] == '-' {
+		p.i++
+		x, err := p.unary()
+		return -x, err
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (float64, error) {
+	p.skipSpace()
+	if p.i == len(p.s) {
+		return 0, p.invalid()
+	}
+	switch c := p.s[p.i]; {
+	case c == '(':
+		p.i++
+		x, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.i == len(p.s) || p.s[p.i] != ')' {
+			return 0, p.invalid()
+		}
+		p.i++
+		return x, nil
+	case isDigit(c) || c == '.':
+		start := p.i
+		for p.i < len(p.s) && (isDigit(p.s[p.i]) || p.s[p.i] == '.') {
+			p.i++
+		}
+		x, err := strconv.ParseFloat(p.s[start:p.i], 64)
+		if err != nil {
+			p.i = start
+			return 0, p.invalid()
+		}
+		return x, nil
+	case isIdentStart(c) || c == '@':
+		start := p.i
+		if c == '@' {
+			p.i++
+		}
+		nameStart := p.i
+		for p.i < len(p.s) && isIdent(p.s[p.i]) {
+			p.i++
+		}
+		name := p.s[nameStart:p.i]
+		t, ok := p.vars[name]
+		if !ok {
+			return 0, &UndefinedVariableError{name, p.pos.advance(p.s[:start])}
+		}
+		x, ok := tokenNumber(t)
+		if !ok {
+			p.i = start
+			return 0, p.invalid()
+		}
+		return x, nil
+	default:
+		return 0, p.invalid()
+	}
+}
+
+func (p *exprParser) skipSpace() {
+	for p.i < len(p.s) && isSpace(p.s[p.i]) {
+		p.i++
+	}
+}
+
+func (p *exprParser) invalid() error {
+	return &InvalidExpressionError{p.s, p.pos.advance(p.s[:p.i])}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdent(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -4,6 +4,8 @@
 package hoi4
 
 import (
+	"errors"
+	"math"
 	"reflect"
 	"slices"
 	"strconv"
@@ -46,30 +48,39 @@ func unmarshal(dec *hoi4text.Decoder, out reflect.Value) error {
 	case reflect.Struct:
 		return unmarshalStruct(dec, out)
 	default:
-		return &InvalidTypeError{out.Type()}
+		return &InvalidTypeError{out.Type(), location(dec)}
 	}
 }
 
 func unmarshalDate(dec *hoi4text.Decoder, out *hoi4date.Date) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
 	}
+	return unmarshalDateToken(dec, t, out)
+}
+
+func unmarshalDateToken(dec *hoi4text.Decoder, t hoi4text.Token, out *hoi4date.Date) error {
 	var x hoi4date.Date
 	var ok bool
 	switch t.ID() {
 	case hoi4text.TokenI32:
 		x, ok = hoi4date.ParseBinary(t.I32())
 		if !ok {
-			return &ParseDateError[int32]{t.I32()}
+			return &ParseDateError[int32]{t.I32(), location(dec)}
 		}
 	case hoi4text.TokenQuoted:
 		x, ok = hoi4date.Parse(t.Quoted())
 		if !ok {
-			return &ParseDateError[string]{t.Quoted()}
+			return &ParseDateError[string]{t.Quoted(), location(dec)}
+		}
+	case hoi4text.TokenUnquoted:
+		x, ok = hoi4date.Parse(t.Unquoted())
+		if !ok {
+			return &ParseDateError[string]{t.Unquoted(), location(dec)}
 		}
 	default:
-		return &InvalidTokenError{t, reflect.TypeFor[hoi4date.Date]()}
+		return &InvalidTokenError{t, reflect.TypeFor[hoi4date.Date](), location(dec)}
 	}
 	*out = x
 	return nil
@@ -78,19 +89,41 @@ func unmarshalDate(dec *hoi4text.Decoder, out *hoi4date.Date) error {
 func unmarshalBool(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
-	} else if t.ID() != hoi4text.TokenBool {
-		return &InvalidTokenError{t, out.Type()}
+		return &ReadTokenError{location(dec), err}
+	}
+	return unmarshalBoolToken(dec, t, out)
+}
+
+func unmarshalBoolToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
+	var x bool
+	switch t.ID() {
+	case hoi4text.TokenBool:
+		x = t.Bool()
+	case hoi4text.TokenUnquoted:
+		switch t.Unquoted() {
+		case "yes":
+			x = true
+		case "no":
+			x = false
+		default:
+			return &InvalidTokenError{t, out.Type(), location(dec)}
+		}
+	default:
+		return &InvalidTokenError{t, out.Type(), location(dec)}
 	}
-	out.SetBool(t.Bool())
+	out.SetBool(x)
 	return nil
 }
 
 func unmarshalInt(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
 	}
+	return unmarshalIntToken(dec, t, out)
+}
+
+func unmarshalIntToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
 	var x int64
 	var ok bool
 	switch t.ID() {
@@ -99,7 +132,7 @@ func unmarshalInt(dec *hoi4text.Decoder, out reflect.Value) error {
 	case hoi4text.TokenU64:
 		x, ok = checked.Cast[int64](t.U64())
 		if !ok {
-			return &OverflowError[uint64]{t.U64(), out.Type()}
+			return &OverflowError[uint64]{t.U64(), out.Type(), location(dec)}
 		}
 	case hoi4text.TokenI32:
 		x = int64(t.I32())
@@ -109,11 +142,25 @@ func unmarshalInt(dec *hoi4text.Decoder, out reflect.Value) error {
 		x = int64(t.F64())
 	case hoi4text.TokenI64:
 		x = t.I64()
+	case hoi4text.TokenUnquoted:
+		f, ok := parseFloat(t.Unquoted())
+		if !ok {
+			return &InvalidTokenError{t, out.Type(), location(dec)}
+		}
+		var err error
+		if x, err = strconv.ParseInt(t.Unquoted(), 10, 64); err != nil {
+			if f < math.MinInt64 || f >= math.MaxInt64 {
+				return &OverflowError[float64]{f, out.Type(), location(dec)}
+			} else if f != math.Trunc(f) {
+				return &InvalidTokenError{t, out.Type(), location(dec)}
+			}
+			x = int64(f)
+		}
 	default:
-		return &InvalidTokenError{t, out.Type()}
+		return &InvalidTokenError{t, out.Type(), location(dec)}
 	}
 	if out.OverflowInt(x) {
-		return &OverflowError[int64]{x, out.Type()}
+		return &OverflowError[int64]{x, out.Type(), location(dec)}
 	}
 	out.SetInt(x)
 	return nil
@@ -122,8 +169,12 @@ func unmarshalInt(dec *hoi4text.Decoder, out reflect.Value) error {
 func unmarshalUint(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
 	}
+	return unmarshalUintToken(dec, t, out)
+}
+
+func unmarshalUintToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
 	var x uint64
 	var ok bool
 	switch t.ID() {
@@ -134,7 +185,7 @@ func unmarshalUint(dec *hoi4text.Decoder, out reflect.Value) error {
 	case hoi4text.TokenI32:
 		x, ok = checked.Cast[uint64](t.I32())
 		if !ok {
-			return &OverflowError[int32]{t.I32(), out.Type()}
+			return &OverflowError[int32]{t.I32(), out.Type(), location(dec)}
 		}
 	case hoi4text.TokenF32:
 		x = uint64(t.F32())
@@ -143,13 +194,27 @@ func unmarshalUint(dec *hoi4text.Decoder, out reflect.Value) error {
 	case hoi4text.TokenI64:
 		x, ok = checked.Cast[uint64](t.I64())
 		if !ok {
-			return &OverflowError[int64]{t.I64(), out.Type()}
+			return &OverflowError[int64]{t.I64(), out.Type(), location(dec)}
+		}
+	case hoi4text.TokenUnquoted:
+		f, ok := parseFloat(t.Unquoted())
+		if !ok {
+			return &InvalidTokenError{t, out.Type(), location(dec)}
+		}
+		var err error
+		if x, err = strconv.ParseUint(t.Unquoted(), 10, 64); err != nil {
+			if f < 0 || f >= math.MaxUint64 {
+				return &OverflowError[float64]{f, out.Type(), location(dec)}
+			} else if f != math.Trunc(f) {
+				return &InvalidTokenError{t, out.Type(), location(dec)}
+			}
+			x = uint64(f)
 		}
 	default:
-		return &InvalidTokenError{t, out.Type()}
+		return &InvalidTokenError{t, out.Type(), location(dec)}
 	}
 	if out.OverflowUint(x) {
-		return &OverflowError[uint64]{x, out.Type()}
+		return &OverflowError[uint64]{x, out.Type(), location(dec)}
 	}
 	out.SetUint(x)
 	return nil
@@ -158,30 +223,48 @@ func unmarshalUint(dec *hoi4text.Decoder, out reflect.Value) error {
 func unmarshalFloat(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
+	}
+	return unmarshalFloatToken(dec, t, out)
+}
+
+func unmarshalFloatToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
+	x, ok := tokenFloat(t)
+	if !ok {
+		return &InvalidTokenError{t, out.Type(), location(dec)}
 	}
-	var x float64
+	if out.OverflowFloat(x) {
+		return &OverflowError[float64]{x, out.Type(), location(dec)}
+	}
+	out.SetFloat(x)
+	return nil
+}
+
+func tokenFloat(t hoi4text.Token) (float64, bool) {
 	switch t.ID() {
 	case hoi4text.TokenU32:
-		x = float64(t.U32())
+		return float64(t.U32()), true
 	case hoi4text.TokenU64:
-		x = float64(t.U64())
+		return float64(t.U64()), true
 	case hoi4text.TokenI32:
-		x = float64(t.I32())
+		return float64(t.I32()), true
 	case hoi4text.TokenF32:
-		x = float64(t.F32())
+		return float64(t.F32()), true
 	case hoi4text.TokenF64:
-		x = t.F64()
+		return t.F64(), true
 	case hoi4text.TokenI64:
-		x = float64(t.I64())
+		return float64(t.I64()), true
+	case hoi4text.TokenUnquoted:
+		return parseFloat(t.Unquoted())
 	default:
-		return &InvalidTokenError{t, out.Type()}
-	}
-	if out.OverflowFloat(x) {
-		return &OverflowError[float64]{x, out.Type()}
+		return 0, false
 	}
-	out.SetFloat(x)
-	return nil
+}
+
+// parseFloat parses the text of a number, rejecting infinities and NaN.
+func parseFloat(s string) (float64, bool) {
+	x, err := strconv.ParseFloat(s, 64)
+	return x, err == nil && !math.IsInf(x, 0) && !math.IsNaN(x)
 }
 
 func unmarshalInterface(dec *hoi4text.Decoder, out reflect.Value) error {
@@ -192,39 +275,73 @@ func unmarshalInterface(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalMap(dec *hoi4text.Decoder, out reflect.Value) error {
-	dec, err := dec.EnterContainer()
+	dec, err := enterContainer(dec)
+	if err != nil {
+		return err
+	}
+	return unmarshalMapContent(dec, out)
+}
+
+func enterContainer(dec *hoi4text.Decoder) (*hoi4text.Decoder, error) {
+	inner, err := dec.EnterContainer()
 	if err != nil {
-		return &EnterContainerError{err}
+		return nil, &EnterContainerError{err, location(dec)}
 	}
-	return unmarshalMapContent(dec, out, hoi4text.ErrEndOfContainer)
+	return inner, nil
 }
 
-func unmarshalMapContent(dec *hoi4text.Decoder, out reflect.Value, stopErr error) (err error) {
+func unmarshalMapContent(dec *hoi4text.Decoder, out reflect.Value) error {
 	typ := out.Type()
 	out.Set(reflect.MakeMap(typ))
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
+	for t, dec := range dec.Entries() {
+		if isUnknownKey(dec, t) {
+			continue
 		}
 		keyPtr := reflect.New(typ.Key())
-		if err := unmarshal(dec, keyPtr); err != nil {
+		if err := unmarshalKey(dec, t, keyPtr.Elem()); err != nil {
 			return err
 		}
-		if id, err := dec.SkipToken(); err != nil {
-			return err
-		} else if id != hoi4text.TokenEqual {
-			return &InvalidKeyValueSeparatorError{id}
-		}
 		elemPtr := reflect.New(typ.Elem())
-		if err := unmarshal(dec, elemPtr); err != nil {
+		if err := unmarshalEntry(dec, elemPtr, dec.Operator()); err != nil {
 			return err
 		}
 		out.SetMapIndex(keyPtr.Elem(), elemPtr.Elem())
 	}
-	if err != stopErr {
-		return err
+	return iterError(dec)
+}
+
+// unmarshalKey unmarshals the key t of an entry. Keys are single tokens, so
+// key types implementing [Unmarshaler], which read from the decoder, are not
+// supported.
+func unmarshalKey(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
+	if out.Type() == reflect.TypeFor[any]() {
+		return unmarshalAnyScalarToken(dec, t, out)
+	}
+	if _, ok := reflect.TypeAssert[Unmarshaler](out.Addr()); ok {
+		return &InvalidTypeError{out.Type(), location(dec)}
+	}
+	if out, ok := reflect.TypeAssert[*hoi4date.Date](out.Addr()); ok {
+		return unmarshalDateToken(dec, t, out)
+	}
+	switch out.Kind() {
+	case reflect.Bool:
+		return unmarshalBoolToken(dec, t, out)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return unmarshalIntToken(dec, t, out)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unmarshalUintToken(dec, t, out)
+	case reflect.Float32, reflect.Float64:
+		return unmarshalFloatToken(dec, t, out)
+	case reflect.String:
+		key, err := objectKey(dec, t)
+		if err != nil {
+			return err
+		}
+		out.SetString(key)
+		return nil
+	default:
+		return &InvalidTypeError{out.Type(), location(dec)}
 	}
-	return nil
 }
 
 func unmarshalPointer(dec *hoi4text.Decoder, out reflect.Value) error {
@@ -235,32 +352,30 @@ func unmarshalPointer(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalSlice(dec *hoi4text.Decoder, out reflect.Value) error {
-	dec, err := dec.EnterContainer()
+	dec, err := enterContainer(dec)
 	if err != nil {
-		return &EnterContainerError{err}
+		return err
 	}
 	elemType := out.Type().Elem()
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
-		}
+	for dec := range dec.Elements() {
 		elemPtr := reflect.New(elemType)
 		if err := unmarshal(dec, elemPtr); err != nil {
 			return err
 		}
 		out.Set(reflect.Append(out, elemPtr.Elem()))
 	}
-	if err != hoi4text.ErrEndOfContainer {
-		return err
-	}
-	return nil
+	return iterError(dec)
 }
 
 func unmarshalString(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
 	}
+	return unmarshalStringToken(dec, t, out)
+}
+
+func unmarshalStringToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
 	var x string
 	switch t.ID() {
 	case hoi4text.TokenQuoted:
@@ -269,11 +384,13 @@ func unmarshalString(dec *hoi4text.Decoder, out reflect.Value) error {
 		x = t.Unquoted()
 	default:
 		if !t.ID().IsID() {
-			return &InvalidTokenError{t, out.Type()}
+			return &InvalidTokenError{t, out.Type(), location(dec)}
 		}
-		x = hoi4text.ResolveToken(t.ID())
-		if x == "" {
-			return &InvalidTokenError{t, out.Type()}
+		x = t.Resolve()
+		if x == "" && dec.UnknownTokens() != nil {
+			return nil // recorded by the decoder
+		} else if x == "" {
+			return &InvalidTokenError{t, out.Type(), location(dec)}
 		}
 	}
 	out.SetString(x)
@@ -281,46 +398,34 @@ func unmarshalString(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalStruct(dec *hoi4text.Decoder, out reflect.Value) error {
-	dec, err := dec.EnterContainer()
+	dec, err := enterContainer(dec)
 	if err != nil {
-		return &EnterContainerError{err}
+		return err
 	}
-	return unmarshalStructContent(dec, out, hoi4text.ErrEndOfContainer)
+	return unmarshalStructContent(dec, out)
 }
 
-func unmarshalStructContent(dec *hoi4text.Decoder, out reflect.Value, stopErr error) error {
+func unmarshalStructContent(dec *hoi4text.Decoder, out reflect.Value) error {
 	fieldIndices, err := fieldIndices(out.Type())
 	if err != nil {
 		return err
 	}
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
+	for t, dec := range dec.Entries() {
+		if isUnknownKey(dec, t) {
+			continue
 		}
-		var key string
-		if err := unmarshalObjectKey(dec, &key); err != nil {
+		key, err := objectKey(dec, t)
+		if err != nil {
 			return err
 		}
-		if id, err := dec.SkipToken(); err != nil {
-			return err
-		} else if id != hoi4text.TokenEqual {
-			return &InvalidKeyValueSeparatorError{id}
-		}
 		if index := fieldIndices[key]; len(index) > 0 {
 			field := fieldByIndex(out, index)
-			if err := unmarshal(dec, field.Addr()); err != nil {
-				return err
-			}
-		} else {
-			if err := dec.SkipValue(); err != nil {
+			if err := unmarshalEntry(dec, field.Addr(), dec.Operator()); err != nil {
 				return err
 			}
 		}
 	}
-	if err != stopErr {
-		return err
-	}
-	return nil
+	return iterError(dec)
 }
 
 func fieldIndices(typ reflect.Type) (m map[string][]int, err error) {
@@ -371,36 +476,51 @@ func fieldIndices(typ reflect.Type) (m map[string][]int, err error) {
 
 var cache sync.Map // map[reflect.Type](map[string][]int | error)
 
-func unmarshalObjectKey(dec *hoi4text.Decoder, out *string) error {
-	t, err := dec.ReadToken()
-	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
-	}
-	var x string
+// isUnknownKey reports whether dec was created with
+// [hoi4text.WithUnknownTokens] and t is an unknown token, in which case the
+// entry is skipped.
+func isUnknownKey(dec *hoi4text.Decoder, t hoi4text.Token) bool {
+	return dec.UnknownTokens() != nil && t.ID().IsID() && t.Resolve() == ""
+}
+
+func objectKey(dec *hoi4text.Decoder, t hoi4text.Token) (string, error) {
 	switch t.ID() {
 	case hoi4text.TokenQuoted:
-		x = t.Quoted()
+		return t.Quoted(), nil
 	case hoi4text.TokenUnquoted:
-		x = t.Unquoted()
+		return t.Unquoted(), nil
 	case hoi4text.TokenU32:
-		x = strconv.FormatUint(uint64(t.U32()), 10)
+		return strconv.FormatUint(uint64(t.U32()), 10), nil
 	case hoi4text.TokenU64:
-		x = strconv.FormatUint(t.U64(), 10)
+		return strconv.FormatUint(t.U64(), 10), nil
 	case hoi4text.TokenI32:
-		x = strconv.FormatInt(int64(t.I32()), 10)
+		return strconv.FormatInt(int64(t.I32()), 10), nil
 	case hoi4text.TokenI64:
-		x = strconv.FormatInt(t.I64(), 10)
-	default:
-		if !t.ID().IsID() {
-			return &InvalidObjectKeyError{t}
-		}
-		x = hoi4text.ResolveToken(t.ID())
-		if x == "" {
-			return &InvalidObjectKeyError{t}
+		return strconv.FormatInt(t.I64(), 10), nil
+	}
+	if x := t.Resolve(); x != "" {
+		return x, nil
+	}
+	return "", &InvalidObjectKeyError{t, location(dec)}
+}
+
+// iterError returns the error that stopped an iteration over the entries or
+// elements read by dec.
+func iterError(dec *hoi4text.Decoder) error {
+	err := dec.Err()
+	if err == nil {
+		return nil
+	}
+	var target *hoi4text.UnexpectedTokenError
+	if errors.As(err, &target) {
+		switch target.Where {
+		case hoi4text.ObjectKey:
+			return &InvalidObjectKeyError{target.Token, location(dec)}
+		case hoi4text.KeyValueSeparator:
+			return &InvalidKeyValueSeparatorError{target.Token, location(dec)}
 		}
 	}
-	*out = x
-	return nil
+	return &ReadTokenError{location(dec), err}
 }
 
 func fieldByIndex(v reflect.Value, index []int) reflect.Value {
@@ -4,7 +4,6 @@
 package hoi4
 
 import (
-	"io"
 	"reflect"
 
 	"github.com/antoniszymanski/hoi4-go/hoi4text"
@@ -13,7 +12,7 @@
 func unmarshalAny(dec *hoi4text.Decoder, out reflect.Value) error {
 	kind, err := dec.PeekKind()
 	if err != nil {
-		return &PeekKindError{err}
+		return &PeekKindError{err, location(dec)}
 	}
 	switch kind {
 	case hoi4text.KindRoot:
@@ -26,33 +25,34 @@ func unmarshalAny(dec *hoi4text.Decoder, out reflect.Value) error {
 		return unmarshalAnyArray(dec, out)
 	case hoi4text.KindObject:
 		return unmarshalAnyObject(dec, out)
+	case hoi4text.KindTaggedContainer:
+		return unmarshalAnyTaggedContainer(dec, out)
 	default:
 		panic("unreachable")
 	}
 }
 
-func unmarshalAnyRoot(dec *hoi4text.Decoder, out reflect.Value) (err error) {
+func unmarshalAnyRoot(dec *hoi4text.Decoder, out reflect.Value) error {
+	return unmarshalAnyContent(dec, out)
+}
+
+func unmarshalAnyContent(dec *hoi4text.Decoder, out reflect.Value) error {
 	x := make(map[string][]any)
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
-		}
-		var key string
-		if err := unmarshalObjectKey(dec, &key); err != nil {
-			return err
+	for t, dec := range dec.Entries() {
+		if isUnknownKey(dec, t) {
+			continue
 		}
-		if id, err := dec.SkipToken(); err != nil {
+		key, err := objectKey(dec, t)
+		if err != nil {
 			return err
-		} else if id != hoi4text.TokenEqual {
-			return &InvalidKeyValueSeparatorError{id}
 		}
 		var value any
-		if err := unmarshalAny(dec, reflect.ValueOf(&value).Elem()); err != nil {
+		if err := unmarshalEntry(dec, reflect.ValueOf(&value), dec.Operator()); err != nil {
 			return err
 		}
 		x[key] = append(x[key], value)
 	}
-	if err != io.EOF {
+	if err := iterError(dec); err != nil {
 		return err
 	}
 	out.Set(reflect.ValueOf(x))
@@ -62,12 +62,18 @@ func unmarshalAnyRoot(dec *hoi4text.Decoder, out reflect.Value) (err error) {
 func unmarshalAnyScalar(dec *hoi4text.Decoder, out reflect.Value) error {
 	t, err := dec.ReadToken()
 	if err != nil {
-		return &ReadTokenError{dec.Offset(), err}
+		return &ReadTokenError{location(dec), err}
 	}
+	return unmarshalAnyScalarToken(dec, t, out)
+}
+
+func unmarshalAnyScalarToken(dec *hoi4text.Decoder, t hoi4text.Token, out reflect.Value) error {
 	var x any
 	switch t.ID() {
-	case hoi4text.TokenOpen, hoi4text.TokenClose, hoi4text.TokenEqual:
-		return &InvalidScalarError{t}
+	case hoi4text.TokenOpen, hoi4text.TokenClose, hoi4text.TokenEqual,
+		hoi4text.TokenLess, hoi4text.TokenLessEqual, hoi4text.TokenGreater,
+		hoi4text.TokenGreaterEqual, hoi4text.TokenNotEqual, hoi4text.TokenExists:
+		return &InvalidScalarError{t, location(dec)}
 	case hoi4text.TokenU32:
 		x = t.U32()
 	case hoi4text.TokenU64:
@@ -95,36 +101,33 @@ func unmarshalAnyScalar(dec *hoi4text.Decoder, out reflect.Value) error {
 
 func unmarshalAnyEmptyContainer(dec *hoi4text.Decoder, out reflect.Value) error {
 	if id, err := dec.SkipToken(); err != nil {
-		return err
+		return &ReadTokenError{location(dec), err}
 	} else if id != hoi4text.TokenOpen {
-		return &InvalidEmptyContainerError{id, FirstTokenOfEmptyContainer}
+		return &InvalidEmptyContainerError{id, FirstTokenOfEmptyContainer, location(dec)}
 	}
 	if id, err := dec.SkipToken(); err != nil {
-		return err
+		return &ReadTokenError{location(dec), err}
 	} else if id != hoi4text.TokenClose {
-		return &InvalidEmptyContainerError{id, LastTokenOfEmptyContainer}
+		return &InvalidEmptyContainerError{id, LastTokenOfEmptyContainer, location(dec)}
 	}
 	out.Set(reflect.ValueOf(struct{}{}))
 	return nil
 }
 
 func unmarshalAnyArray(dec *hoi4text.Decoder, out reflect.Value) error {
-	dec, err := dec.EnterContainer()
+	dec, err := enterContainer(dec)
 	if err != nil {
-		return &EnterContainerError{err}
+		return err
 	}
 	var x []any
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
-		}
+	for dec := range dec.Elements() {
 		var elem any
 		if err := unmarshalAny(dec, reflect.ValueOf(&elem).Elem()); err != nil {
 			return err
 		}
 		x = append(x, elem)
 	}
-	if err != hoi4text.ErrEndOfContainer {
+	if err := iterError(dec); err != nil {
 		return err
 	}
 	out.Set(reflect.ValueOf(x))
@@ -132,31 +135,16 @@ func unmarshalAnyArray(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalAnyObject(dec *hoi4text.Decoder, out reflect.Value) error {
-	dec, err := dec.EnterContainer()
+	dec, err := enterContainer(dec)
 	if err != nil {
-		return &EnterContainerError{err}
-	}
-	x := make(map[string][]any)
-	for {
-		if err = dec.IsEndOfContainer(); err != nil {
-			break
-		}
-		var key string
-		if err := unmarshalObjectKey(dec, &key); err != nil {
-			return err
-		}
-		if id, err := dec.SkipToken(); err != nil {
-			return err
-		} else if id != hoi4text.TokenEqual {
-			return &InvalidKeyValueSeparatorError{id}
-		}
-		var value any
-		if err := unmarshalAny(dec, reflect.ValueOf(&value).Elem()); err != nil {
-			return err
-		}
-		x[key] = append(x[key], value)
+		return err
 	}
-	if err != hoi4text.ErrEndOfContainer {
+	return unmarshalAnyContent(dec, out)
+}
+
+func unmarshalAnyTaggedContainer(dec *hoi4text.Decoder, out reflect.Value) error {
+	var x Color
+	if err := x.UnmarshalHOI4(dec); err != nil {
 		return err
 	}
 	out.Set(reflect.ValueOf(x))
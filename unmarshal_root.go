@@ -4,7 +4,6 @@
 package hoi4
 
 import (
-	"io"
 	"reflect"
 
 	"github.com/antoniszymanski/hoi4-go/hoi4text"
@@ -27,7 +26,7 @@ func unmarshalRoot(dec *hoi4text.Decoder, out reflect.Value) error {
 	case reflect.Struct:
 		return unmarshalRootStruct(dec, out)
 	default:
-		return &InvalidRootTypeError{out.Type()}
+		return &InvalidRootTypeError{out.Type(), location(dec)}
 	}
 }
 
@@ -39,7 +38,7 @@ func unmarshalRootInterface(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalRootMap(dec *hoi4text.Decoder, out reflect.Value) error {
-	return unmarshalMapContent(dec, out, io.EOF)
+	return unmarshalMapContent(dec, out)
 }
 
 func unmarshalRootPointer(dec *hoi4text.Decoder, out reflect.Value) error {
@@ -50,5 +49,5 @@ func unmarshalRootPointer(dec *hoi4text.Decoder, out reflect.Value) error {
 }
 
 func unmarshalRootStruct(dec *hoi4text.Decoder, out reflect.Value) error {
-	return unmarshalStructContent(dec, out, io.EOF)
+	return unmarshalStructContent(dec, out)
 }